@@ -0,0 +1,68 @@
+// Copyright 2017 The go-importlint Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package analyzer provides the importlint analysis.Analyzer.
+package analyzer
+
+import (
+	"go/ast"
+	"go/token"
+
+	"golang.org/x/tools/go/analysis"
+
+	importlint "github.com/zchee/go-importlint"
+)
+
+const doc = `check imported packages according to the layer config
+
+The layer of each package is matched by the import path with the packages
+patterns or its package name, and the layer of imported package is matched by
+the packages patterns or the deepest element of the import path defined in the
+layer config.`
+
+// New returns the analysis.Analyzer which checks imports by conf.
+func New(conf *importlint.Config) *analysis.Analyzer {
+	return &analysis.Analyzer{
+		Name: "importlint",
+		Doc:  doc,
+		Run: func(pass *analysis.Pass) (interface{}, error) {
+			return run(pass, conf)
+		},
+	}
+}
+
+// run checks the files of pass by importlint.CheckDependency, so the external
+// test package is checked as the package under test, and the imports other than
+// the project packages are checked only if the packages patterns match them.
+func run(pass *analysis.Pass, conf *importlint.Config) (interface{}, error) {
+	if len(pass.Files) == 0 {
+		return nil, nil
+	}
+
+	pkg := &ast.Package{Name: pass.Pkg.Name(), Files: make(map[string]*ast.File)}
+	imports := make(map[token.Position]token.Pos)
+	for _, file := range pass.Files {
+		pkg.Files[pass.Fset.Position(file.Package).Filename] = file
+		for _, imppkg := range file.Imports {
+			imports[pass.Fset.Position(imppkg.Pos())] = imppkg.Pos()
+		}
+	}
+
+	bctx := importlint.NewBuildContext(".")
+	violations, err := importlint.CheckDependency(pass.Fset, &bctx, map[string]*ast.Package{pkg.Name: pkg}, conf)
+	if _, ok := err.(importlint.Diagnostics); err != nil && !ok {
+		return nil, err
+	}
+	// the Diagnostics of unresolved imports are left to the type checker
+
+	for _, v := range violations {
+		pos, ok := imports[v.Pos]
+		if !ok {
+			continue
+		}
+		pass.Reportf(pos, "%s", v.Message())
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,37 @@
+// Copyright 2017 The go-importlint Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package analyzer_test
+
+import (
+	"go/build"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+
+	importlint "github.com/zchee/go-importlint"
+	"github.com/zchee/go-importlint/analyzer"
+)
+
+func TestAnalyzer(t *testing.T) {
+	testdata, err := filepath.Abs("testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	gopath := build.Default.GOPATH
+	build.Default.GOPATH = testdata
+	defer func() { build.Default.GOPATH = gopath }()
+
+	conf := &importlint.Config{
+		Project: "app",
+		Layers: map[string]importlint.LayerConfig{
+			"domain":         {},
+			"infrastructure": {Allow: []string{"domain"}},
+		},
+	}
+	// the vendored github.com/x/infrastructure is not in the infrastructure layer,
+	// and the external test package is checked as the domain layer.
+	analysistest.Run(t, testdata, analyzer.New(conf), "app/domain", "app/infrastructure")
+}
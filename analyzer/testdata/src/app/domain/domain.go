@@ -0,0 +1,7 @@
+package domain
+
+import (
+	_ "app/infrastructure" // want `domain layer must not import "app/infrastructure" \(infrastructure layer\)`
+
+	_ "github.com/x/infrastructure"
+)
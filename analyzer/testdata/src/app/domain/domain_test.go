@@ -0,0 +1,6 @@
+package domain_test
+
+import (
+	_ "app/domain"
+	_ "app/infrastructure" // want `domain layer must not import "app/infrastructure" \(infrastructure layer\)`
+)
@@ -0,0 +1,90 @@
+// Copyright 2017 The go-importlint Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package importlint
+
+import (
+	"fmt"
+	"go/build"
+	"path/filepath"
+	"strings"
+)
+
+// ImportClass is the class of the imported package.
+type ImportClass int
+
+const (
+	// ProjectImport is the package of the linting project.
+	ProjectImport ImportClass = iota
+	// StdlibImport is the standard library package.
+	StdlibImport
+	// ThirdPartyImport is the package of the other projects, including the vendored packages.
+	ThirdPartyImport
+)
+
+func (c ImportClass) String() string {
+	switch c {
+	case ProjectImport:
+		return "project"
+	case StdlibImport:
+		return "stdlib"
+	case ThirdPartyImport:
+		return "third-party"
+	}
+	return fmt.Sprintf("ImportClass(%d)", int(c))
+}
+
+// Classify returns the class of importPath imported from the root directory.
+// The project is the import path inferred by Project.
+func (bc *BuildContext) Classify(importPath string) ImportClass {
+	project, _ := bc.Project(nil)
+	return bc.classify(project, importPath, bc.root)
+}
+
+// classify returns the class of importPath imported from srcDir.
+//
+// The standard library is determined by the Goroot flag of the package found by
+// build.Context.Import with FindOnly, not by the heuristics of the import path.
+// The vendored packages are the third-party even if they are in GOROOT/src/vendor,
+// so the vendored packages which look like the standard library are too.
+//
+// If project is empty, such as the gb project, the packages in the root directory
+// of bc except the vendor directory trees are the project packages.
+func (bc *BuildContext) classify(project, importPath, srcDir string) ImportClass {
+	resolved, dir := importPath, ""
+	if pkg, err := bc.ctxt.Import(importPath, srcDir, build.FindOnly); err == nil {
+		if pkg.ImportPath != "" && pkg.ImportPath != "." {
+			resolved = pkg.ImportPath
+		}
+		dir = pkg.Dir
+		if isVendored(strings.TrimPrefix(resolved, project)) {
+			return ThirdPartyImport
+		}
+		if pkg.Goroot {
+			return StdlibImport
+		}
+	}
+
+	if project == "" {
+		if dir != "" && hasPathPrefix(dir, bc.root) {
+			if rel, err := filepath.Rel(bc.root, dir); err == nil && !isVendored(filepath.ToSlash(rel)) {
+				return ProjectImport
+			}
+		}
+		return ThirdPartyImport
+	}
+	if hasImportPathPrefix(resolved, project) && !isVendored(strings.TrimPrefix(resolved, project)) {
+		return ProjectImport
+	}
+	return ThirdPartyImport
+}
+
+// hasImportPathPrefix reports whether importPath is prefix or in the subdirectory of prefix.
+func hasImportPathPrefix(importPath, prefix string) bool {
+	return importPath == prefix || strings.HasPrefix(importPath, prefix+"/")
+}
+
+func isVendored(importPath string) bool {
+	return strings.HasPrefix(importPath, "vendor/") || strings.Contains(importPath, "/vendor/")
+}
@@ -0,0 +1,81 @@
+// Copyright 2017 The go-importlint Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package importlint
+
+import (
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestClassify(t *testing.T) {
+	gopath, err := filepath.Abs(filepath.Join("testdata", "resolve"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	gb, err := filepath.Abs(filepath.Join("testdata", "gb"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		bc         BuildContext
+		importPath string
+		want       ImportClass
+	}{
+		{"stdlib", NewBuildContext(filepath.Join(gopath, "src", "app"), WithGOPATH(gopath)), "fmt", StdlibImport},
+		// the vendored package in GOROOT/src/vendor is not the standard library.
+		{"goroot vendor", NewBuildContext(filepath.Join(gopath, "src", "app"), WithGOPATH(gopath)), "vendor/golang.org/x/net/http/httpguts", ThirdPartyImport},
+		{"project", NewBuildContext(filepath.Join(gopath, "src", "app"), WithGOPATH(gopath)), "app/cmd", ProjectImport},
+		{"vendored", NewBuildContext(filepath.Join(gopath, "src", "app"), WithGOPATH(gopath)), "lib", ThirdPartyImport},
+		{"src vendor", NewBuildContext(filepath.Join(gopath, "src", "app"), WithGOPATH(gopath)), "shared", ThirdPartyImport},
+		{"other project", NewBuildContext(filepath.Join(gopath, "src", "app"), WithGOPATH(gopath)), "other", ThirdPartyImport},
+		{"not found", NewBuildContext(filepath.Join(gopath, "src", "app"), WithGOPATH(gopath)), "example.com/none", ThirdPartyImport},
+		// the gb project has no project import path.
+		{"gb project", NewBuildContext(gb), "svc/lib", ProjectImport},
+		{"gb vendored", NewBuildContext(gb), "github.com/x/dep", ThirdPartyImport},
+		{"gb stdlib", NewBuildContext(gb), "fmt", StdlibImport},
+	}
+	for _, tt := range tests {
+		if got := tt.bc.Classify(tt.importPath); got != tt.want {
+			t.Errorf("%s: Classify(%q) = %v, want %v", tt.name, tt.importPath, got, tt.want)
+		}
+	}
+}
+
+func TestNewGraphGb(t *testing.T) {
+	root, err := filepath.Abs(filepath.Join("testdata", "gb"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	bc := NewBuildContext(root)
+	pkgs, err := bc.FindAllPackage(nil, ExcludeVendor)
+	if err != nil {
+		t.Fatalf("FindAllPackage() error = %v", err)
+	}
+	g, err := NewGraph(token.NewFileSet(), &bc, pkgs, nil)
+	if err != nil {
+		t.Fatalf("NewGraph() error = %v", err)
+	}
+
+	got := make(map[string]ImportClass)
+	for path, n := range g.Nodes {
+		got[path] = n.Class
+	}
+	want := map[string]ImportClass{
+		"svc":              ProjectImport,
+		"svc/lib":          ProjectImport,
+		"github.com/x/dep": ThirdPartyImport,
+		"fmt":              StdlibImport,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("classes of the nodes = %v, want %v", got, want)
+	}
+	if chains := g.LongestChains(); len(chains["svc"]) != 2 {
+		t.Errorf("LongestChains()[svc] = %v, want svc -> svc/lib", chains["svc"])
+	}
+}
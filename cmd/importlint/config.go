@@ -0,0 +1,153 @@
+// Copyright 2017 The go-importlint Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/pkg/errors"
+
+	importlint "github.com/zchee/go-importlint"
+)
+
+const configUsage = `usage: importlint config <command>
+
+commands:
+  migrate   rewrite the config into the latest schema version
+  print     print the resolved config with the origin of each entry
+  schema    print the JSON Schema of the config
+`
+
+// runConfig runs the config subcommands.
+func runConfig(args []string) {
+	fs := flag.NewFlagSet("config", flag.ExitOnError)
+	fs.Usage = func() { fmt.Fprint(os.Stderr, configUsage) }
+	configFlag(fs)
+	fs.Parse(args)
+
+	dir := "."
+	if fs.NArg() > 1 {
+		dir = fs.Arg(1)
+	}
+
+	switch fs.Arg(0) {
+	case "migrate":
+		migrateConfig(fs.Args()[1:])
+	case "print":
+		conf, _ := loadConfig(*config, dir)
+		printConfig(os.Stdout, conf)
+	case "schema":
+		os.Stdout.Write(importlint.ConfigSchema)
+	default:
+		fs.Usage()
+		log.Fatalf("unknown config command %q", fs.Arg(0))
+	}
+}
+
+// migrateConfig rewrites the config file into the latest schema version.
+func migrateConfig(args []string) {
+	fs := flag.NewFlagSet("config migrate", flag.ExitOnError)
+	write := fs.Bool("w", false, "write the result to the config file instead of stdout")
+	fs.Parse(args)
+
+	path := *config
+	if fs.NArg() > 0 {
+		path = fs.Arg(0)
+	}
+	if path == "" {
+		found, err := importlint.FindConfig(".")
+		if err != nil {
+			log.Fatal(err)
+		}
+		path = found
+	}
+
+	src, err := ioutil.ReadFile(path)
+	if err != nil {
+		log.Fatal(errors.Wrapf(err, "could not read %s", path))
+	}
+	out, err := importlint.MigrateConfig(src, filepath.Ext(path))
+	if err != nil {
+		log.Fatal(errors.Wrapf(err, "could not migrate %s", path))
+	}
+
+	if !*write {
+		os.Stdout.Write(out)
+		return
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := ioutil.WriteFile(path, out, fi.Mode()); err != nil {
+		log.Fatal(errors.Wrapf(err, "could not write %s", path))
+	}
+}
+
+// printConfig prints conf as YAML, and comments the origin of each entry.
+func printConfig(w io.Writer, conf *importlint.Config) {
+	if conf.Extends != "" {
+		fmt.Fprintf(w, "# extends: %s\n", conf.Extends)
+	}
+	for _, inc := range conf.Include {
+		fmt.Fprintf(w, "# include: %s\n", inc)
+	}
+
+	fmt.Fprintf(w, "version: %d\n", conf.Version)
+	fmt.Fprintf(w, "project: %q  # %s\n", conf.Project, conf.Origin("project"))
+	fmt.Fprintln(w, "layers:")
+
+	layers := make([]string, 0, len(conf.Layers))
+	for layer := range conf.Layers {
+		layers = append(layers, layer)
+	}
+	sort.Strings(layers)
+	for _, layer := range layers {
+		key := "layer." + layer
+		lc := conf.Layers[layer]
+		if len(lc.Allow) == 0 && len(lc.Packages) == 0 && lc.MaxDepth == 0 {
+			fmt.Fprintf(w, "  %s: {}  # %s\n", layer, conf.Origin(key))
+			continue
+		}
+		fmt.Fprintf(w, "  %s:  # %s\n", layer, conf.Origin(key))
+		if len(lc.Allow) > 0 {
+			fmt.Fprintln(w, "    allow:")
+			for _, dep := range lc.Allow {
+				fmt.Fprintf(w, "      - %s  # %s\n", dep, conf.Origin(key+"."+dep))
+			}
+		}
+		if len(lc.Packages) > 0 {
+			fmt.Fprintln(w, "    packages:")
+			for _, pkg := range lc.Packages {
+				fmt.Fprintf(w, "      - %q  # %s\n", pkg, conf.Origin("packages."+layer+"."+pkg))
+			}
+		}
+		if lc.MaxDepth != 0 {
+			fmt.Fprintf(w, "    maxDepth: %d  # %s\n", lc.MaxDepth, conf.Origin("maxDepth."+layer))
+		}
+	}
+
+	if len(conf.Components) > 0 {
+		fmt.Fprintln(w, "components:")
+		comps := make([]string, 0, len(conf.Components))
+		for comp := range conf.Components {
+			comps = append(comps, comp)
+		}
+		sort.Strings(comps)
+		for _, comp := range comps {
+			fmt.Fprintf(w, "  %s:  # %s\n", comp, conf.Origin("component."+comp))
+			for _, pattern := range conf.Components[comp] {
+				fmt.Fprintf(w, "    - %q  # %s\n", pattern, conf.Origin("component."+comp+"."+pattern))
+			}
+		}
+	}
+}
@@ -0,0 +1,150 @@
+// Copyright 2017 The go-importlint Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+	importlint "github.com/zchee/go-importlint"
+)
+
+// packageDepth is the longest import chain of the package.
+type packageDepth struct {
+	ImportPath string   `json:"importPath"`
+	Layer      string   `json:"layer,omitempty"`
+	Depth      int      `json:"depth"`
+	Chain      []string `json:"chain"`
+	// MaxDepth is the maxDepth of the layer, and Exceeded reports whether Depth exceeds it.
+	MaxDepth int  `json:"maxDepth,omitempty"`
+	Exceeded bool `json:"exceeded,omitempty"`
+}
+
+func (d packageDepth) String() string {
+	return fmt.Sprintf("%s: depth %d exceeds maxDepth %d of %s layer (%s)", d.ImportPath, d.Depth, d.MaxDepth, d.Layer, strings.Join(d.Chain, " -> "))
+}
+
+// runDepth prints the longest import chain of each project package, and reports
+// the packages whose depth exceeds the maxDepth of the layer.
+func runDepth(args []string) {
+	fs := flag.NewFlagSet("depth", flag.ExitOnError)
+	configFlag(fs)
+	format := fs.String("format", "text", "output `format`: text or json")
+	fs.Parse(args)
+
+	path := pathArg(fs)
+
+	conf, confPath := loadConfig(*config, path)
+	resolver := importlint.NewConfigResolver(path, confPath, conf)
+	bc := importlint.NewBuildContext(path)
+	bc = bc.WithPlatform(*goos, *goarch, tags)
+	g, diags := newGraph(&bc, conf)
+	for _, d := range diags {
+		fmt.Fprintln(os.Stderr, d)
+	}
+
+	for _, scc := range g.StronglyConnectedComponents() {
+		if len(scc) > 1 {
+			fmt.Fprintf(os.Stderr, "import cycle of %s is not followed by the chains\n", strings.Join(scc, ", "))
+		}
+	}
+
+	depths, err := packageDepths(g, resolver)
+	if err != nil {
+		log.Fatal(err)
+	}
+	exceeded := 0
+	for _, d := range depths {
+		if d.Exceeded {
+			exceeded++
+		}
+	}
+
+	w := bufio.NewWriter(os.Stdout)
+	if err := writeDepths(w, depths, *format); err != nil {
+		log.Fatal(err)
+	}
+	if err := w.Flush(); err != nil {
+		log.Fatal(err)
+	}
+
+	os.Exit(exitStatus(exceeded > 0, len(diags) > 0))
+}
+
+// packageDepths returns the depth of each project package of g sorted by the
+// depth in descending order, with the maxDepth of the layer in the config which
+// resolver resolves for the package.
+func packageDepths(g *importlint.Graph, resolver *importlint.ConfigResolver) ([]packageDepth, error) {
+	var depths []packageDepth
+	for importPath, chain := range g.LongestChains() {
+		n := g.Nodes[importPath]
+		d := packageDepth{
+			ImportPath: importPath,
+			Layer:      n.Layer,
+			Depth:      len(chain) - 1,
+			Chain:      chain,
+		}
+		if n.Dir != "" {
+			// The layer and its maxDepth are of the config governing the package.
+			pconf, _, err := resolver.Resolve(n.Dir)
+			if err != nil {
+				return nil, errors.Wrapf(err, "could not resolve config of %s", n.Dir)
+			}
+			if pconf.Project == "" {
+				c := *pconf
+				c.Project = g.Project
+				pconf = &c
+			}
+			d.Layer, _ = pconf.PackageLayer(importPath, n.Name)
+			d.MaxDepth = pconf.Layers[d.Layer].MaxDepth
+			d.Exceeded = d.MaxDepth > 0 && d.Depth > d.MaxDepth
+		}
+		depths = append(depths, d)
+	}
+	sort.Slice(depths, func(i, j int) bool {
+		if depths[i].Depth != depths[j].Depth {
+			return depths[i].Depth > depths[j].Depth
+		}
+		return depths[i].ImportPath < depths[j].ImportPath
+	})
+	return depths, nil
+}
+
+func writeDepths(w io.Writer, depths []packageDepth, format string) error {
+	switch format {
+	case "text":
+		tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+		fmt.Fprintln(tw, "DEPTH\tPACKAGE\tCHAIN")
+		for _, d := range depths {
+			fmt.Fprintf(tw, "%d\t%s\t%s\n", d.Depth, d.ImportPath, strings.Join(d.Chain[1:], " -> "))
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+		for _, d := range depths {
+			if d.Exceeded {
+				fmt.Fprintln(w, d)
+			}
+		}
+		return nil
+	case "json":
+		if depths == nil {
+			depths = []packageDepth{}
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(depths)
+	}
+	return errors.Errorf("unknown depth format %q", format)
+}
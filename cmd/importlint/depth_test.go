@@ -0,0 +1,55 @@
+// Copyright 2017 The go-importlint Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	importlint "github.com/zchee/go-importlint"
+)
+
+func TestPackageDepths(t *testing.T) {
+	gopath, err := filepath.Abs(filepath.Join("..", "..", "testdata", "graph"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := filepath.Join(gopath, "src", "shop")
+	conf := &importlint.Config{
+		Layers: map[string]importlint.LayerConfig{
+			"domain":         {},
+			"application":    {Allow: []string{"domain"}, MaxDepth: 1},
+			"infrastructure": {Allow: []string{"domain", "application"}, MaxDepth: 1},
+		},
+	}
+	bc := importlint.NewBuildContext(root, importlint.WithGOROOT(""), importlint.WithGOPATH(gopath))
+	pkgs, err := bc.FindAllPackage(nil, importlint.ExcludeVendor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := importlint.NewGraph(token.NewFileSet(), &bc, pkgs, conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := packageDepths(g, importlint.NewConfigResolver(root, filepath.Join(root, ".importlint.yaml"), conf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the external test package of domain imports infrastructure, which is not
+	// the import chain of domain.
+	want := []packageDepth{
+		{ImportPath: "shop/infrastructure", Layer: "infrastructure", Depth: 2, Chain: []string{"shop/infrastructure", "shop/application", "shop/domain"}, MaxDepth: 1, Exceeded: true},
+		{ImportPath: "shop/application", Layer: "application", Depth: 1, Chain: []string{"shop/application", "shop/domain"}, MaxDepth: 1},
+		{ImportPath: "shop/payments", Depth: 1, Chain: []string{"shop/payments", "shop/billing"}},
+		{ImportPath: "shop/billing", Chain: []string{"shop/billing"}},
+		{ImportPath: "shop/domain", Layer: "domain", Chain: []string{"shop/domain"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("packageDepths() =\n%+v\nwant\n%+v", got, want)
+	}
+}
@@ -0,0 +1,244 @@
+// Copyright 2017 The go-importlint Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"html/template"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+
+	importlint "github.com/zchee/go-importlint"
+)
+
+// dsm is the dependency structure matrix. The row imports the column, and the
+// names are ordered so that the cells above the diagonal are the imports of the
+// later layers.
+type dsm struct {
+	Names []string
+	// Cells is the number of imports from the row to the column.
+	Cells [][]int
+	// Violations reports whether the imports from the row to the column violate the layers.
+	Violations [][]bool
+}
+
+// runDSM prints the dependency structure matrix of the layers or packages.
+func runDSM(args []string) {
+	fs := flag.NewFlagSet("dsm", flag.ExitOnError)
+	configFlag(fs)
+	format := fs.String("format", "text", "output `format`: text, csv or html")
+	level := fs.String("level", "layer", "matrix `level`: layer or package")
+	fs.Parse(args)
+
+	path := pathArg(fs)
+
+	g, conf, diags := loadGraph(path)
+	for _, d := range diags {
+		fmt.Fprintln(os.Stderr, d)
+	}
+
+	var m *dsm
+	switch *level {
+	case "layer":
+		m = layerDSM(g, conf)
+	case "package":
+		m = packageDSM(g, conf)
+	default:
+		log.Fatalf("unknown dsm level %q", *level)
+	}
+
+	w := bufio.NewWriter(os.Stdout)
+	if err := m.write(w, *format); err != nil {
+		log.Fatal(err)
+	}
+	if err := w.Flush(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// layeredPackages returns the graph of the project packages which are in any layers.
+func layeredPackages(g *importlint.Graph) *importlint.Graph {
+	return g.Aggregate(func(n *importlint.Node) string {
+		if n.Class != importlint.ProjectImport || n.Layer == "" {
+			return ""
+		}
+		return n.ImportPath
+	})
+}
+
+// layerDSM returns the matrix of the layers ordered by Config.LayerOrder.
+// The cell is the number of the package imports between the layers.
+func layerDSM(g *importlint.Graph, conf *importlint.Config) *dsm {
+	m := newDSM(conf.LayerOrder())
+	index := make(map[string]int, len(m.Names))
+	for i, name := range m.Names {
+		index[name] = i
+	}
+
+	pg := layeredPackages(g)
+	for _, e := range pg.Edges() {
+		from, to := pg.Nodes[e.From].Layer, pg.Nodes[e.To].Layer
+		if from == to {
+			continue
+		}
+		i, j := index[from], index[to]
+		m.Cells[i][j]++
+		m.Violations[i][j] = !conf.CanImport(from, to)
+	}
+	return m
+}
+
+// packageDSM returns the matrix of the project packages in any layers ordered by
+// the layer, and by the imports in the same layer. The cell is the number of the
+// import declarations between the packages.
+func packageDSM(g *importlint.Graph, conf *importlint.Config) *dsm {
+	pg := layeredPackages(g)
+
+	layerIndex := make(map[string]int, len(conf.Layers))
+	for i, layer := range conf.LayerOrder() {
+		layerIndex[layer] = i
+	}
+	topo := make(map[string]int, len(pg.Nodes))
+	var names []string
+	for i, scc := range pg.StronglyConnectedComponents() {
+		for _, name := range scc {
+			topo[name] = i
+			names = append(names, name)
+		}
+	}
+	sort.SliceStable(names, func(i, j int) bool {
+		return layerIndex[pg.Nodes[names[i]].Layer] < layerIndex[pg.Nodes[names[j]].Layer]
+	})
+
+	m := newDSM(names)
+	index := make(map[string]int, len(names))
+	for i, name := range names {
+		index[name] = i
+	}
+	for _, e := range pg.Edges() {
+		i, j := index[e.From], index[e.To]
+		m.Cells[i][j] = len(e.Positions)
+		m.Violations[i][j] = violates(pg, conf, e)
+	}
+	return m
+}
+
+func newDSM(names []string) *dsm {
+	m := &dsm{
+		Names:      names,
+		Cells:      make([][]int, len(names)),
+		Violations: make([][]bool, len(names)),
+	}
+	for i := range names {
+		m.Cells[i] = make([]int, len(names))
+		m.Violations[i] = make([]bool, len(names))
+	}
+	return m
+}
+
+func (m *dsm) write(w io.Writer, format string) error {
+	switch format {
+	case "text":
+		return m.writeText(w)
+	case "csv":
+		return m.writeCSV(w)
+	case "html":
+		return dsmTemplate.Execute(w, m)
+	}
+	return errors.Errorf("unknown dsm format %q", format)
+}
+
+// writeText writes m as the table whose columns are numbered by the row.
+// The violating cells are marked by "*".
+func (m *dsm) writeText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 1, ' ', tabwriter.AlignRight)
+	fmt.Fprint(tw, "\t\t")
+	for i := range m.Names {
+		fmt.Fprintf(tw, "%d\t", i+1)
+	}
+	fmt.Fprintln(tw)
+	for i, name := range m.Names {
+		fmt.Fprintf(tw, "%d\t%s \t", i+1, name)
+		for j := range m.Names {
+			switch {
+			case i == j:
+				fmt.Fprint(tw, "-\t")
+			case m.Cells[i][j] == 0:
+				fmt.Fprint(tw, ".\t")
+			case m.Violations[i][j]:
+				fmt.Fprintf(tw, "%d*\t", m.Cells[i][j])
+			default:
+				fmt.Fprintf(tw, "%d\t", m.Cells[i][j])
+			}
+		}
+		fmt.Fprintln(tw)
+	}
+	return tw.Flush()
+}
+
+// writeCSV writes m as the CSV whose first row and column are the names.
+// The violating cells are marked by "*" as well as writeText.
+func (m *dsm) writeCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write(append([]string{""}, m.Names...))
+	for i, name := range m.Names {
+		record := []string{name}
+		for j := range m.Names {
+			cell := strconv.Itoa(m.Cells[i][j])
+			if m.Violations[i][j] {
+				cell += "*"
+			}
+			record = append(record, cell)
+		}
+		cw.Write(record)
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+var dsmTemplate = template.Must(template.New("dsm").Funcs(template.FuncMap{
+	"inc": func(i int) int { return i + 1 },
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>importlint dependency structure matrix</title>
+<style>
+body { font-family: sans-serif; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 2px 6px; text-align: center; }
+th.name { text-align: left; }
+td.diagonal { background: #eee; }
+td.upper { background: #fff4e5; }
+td.violation { background: #f66; color: #fff; font-weight: bold; }
+</style>
+</head>
+<body>
+<p>The row imports the column. The cells above the diagonal are the imports of the later layers.</p>
+<table>
+<tr><th></th><th></th>{{range $i, $_ := .Names}}<th>{{inc $i}}</th>{{end}}</tr>
+{{- $m := .}}
+{{range $i, $name := .Names}}<tr><th>{{inc $i}}</th><th class="name">{{$name}}</th>
+{{- range $j, $n := index $m.Cells $i}}
+{{- if eq $i $j}}<td class="diagonal"></td>
+{{- else if index (index $m.Violations $i) $j}}<td class="violation">{{$n}}</td>
+{{- else if and (gt $j $i) (gt $n 0)}}<td class="upper">{{$n}}</td>
+{{- else if gt $n 0}}<td>{{$n}}</td>
+{{- else}}<td></td>
+{{- end}}
+{{- end}}</tr>
+{{end}}</table>
+</body>
+</html>
+`))
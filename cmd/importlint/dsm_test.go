@@ -0,0 +1,48 @@
+// Copyright 2017 The go-importlint Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDSMWrite(t *testing.T) {
+	m := newDSM([]string{"presentation", "application", "domain"})
+	m.Cells[0][1] = 2
+	m.Cells[1][2] = 1
+	m.Cells[2][0] = 3
+	m.Violations[2][0] = true
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{
+			format: "text",
+			want: "                  1 2 3\n" +
+				" 1 presentation   - 2 .\n" +
+				" 2  application   . - 1\n" +
+				" 3       domain  3* . -\n",
+		},
+		{
+			format: "csv",
+			want: ",presentation,application,domain\n" +
+				"presentation,0,2,0\n" +
+				"application,0,0,1\n" +
+				"domain,3*,0,0\n",
+		},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		if err := m.write(&buf, tt.format); err != nil {
+			t.Errorf("%s: write() error = %v", tt.format, err)
+			continue
+		}
+		if got := buf.String(); got != tt.want {
+			t.Errorf("%s: write() = %q, want %q", tt.format, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,219 @@
+// Copyright 2017 The go-importlint Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"encoding/xml"
+	"flag"
+	"fmt"
+	"go/token"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	importlint "github.com/zchee/go-importlint"
+)
+
+// layerPalette is the fill colors of the layers, assigned in order of the layer name.
+var layerPalette = []string{
+	"#8dd3c7", "#ffffb3", "#bebada", "#fb8072", "#80b1d3", "#fdb462",
+	"#b3de69", "#fccde5", "#bc80bd", "#ccebc5", "#ffed6f", "#d9d9d9",
+}
+
+// noLayerColor is the fill color of the packages not in any layers.
+const noLayerColor = "#ffffff"
+
+// runGraph prints the import graph.
+func runGraph(args []string) {
+	fs := flag.NewFlagSet("graph", flag.ExitOnError)
+	configFlag(fs)
+	format := fs.String("format", "dot", "output `format`: dot, mermaid or graphml")
+	level := fs.String("level", "package", "graph `level`: package or layer")
+	external := fs.Bool("external", false, "include the standard library and third-party packages in the package level graph")
+	fs.Parse(args)
+
+	path := pathArg(fs)
+
+	g, conf, diags := loadGraph(path)
+	for _, d := range diags {
+		fmt.Fprintln(os.Stderr, d)
+	}
+
+	switch *level {
+	case "package":
+		g = g.Aggregate(func(n *importlint.Node) string {
+			if !*external && n.Class != importlint.ProjectImport {
+				return ""
+			}
+			return n.ImportPath
+		})
+	case "layer":
+		g = g.Aggregate(func(n *importlint.Node) string { return n.Layer })
+	default:
+		log.Fatalf("unknown graph level %q", *level)
+	}
+
+	w := bufio.NewWriter(os.Stdout)
+	if err := writeGraph(w, g, conf, *format); err != nil {
+		log.Fatal(err)
+	}
+	if err := w.Flush(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// loadGraph returns the import graph of the packages in path, and the config of path.
+// The packages are selected by the -goos, -goarch and -tags flags.
+func loadGraph(path string) (*importlint.Graph, *importlint.Config, importlint.Diagnostics) {
+	conf, _ := loadConfig(*config, path)
+	bc := importlint.NewBuildContext(path)
+	bc = bc.WithPlatform(*goos, *goarch, tags)
+	g, diags := newGraph(&bc, conf)
+	return g, conf, diags
+}
+
+// newGraph returns the import graph of the packages found in bc.
+func newGraph(bc *importlint.BuildContext, conf *importlint.Config) (*importlint.Graph, importlint.Diagnostics) {
+	pkgs, err := bc.FindAllPackage(nil, findMode(importlint.ExcludeVendor))
+	diags := importlint.DiagnosticsOf(err)
+
+	g, err := importlint.NewGraph(token.NewFileSet(), bc, pkgs, conf)
+	diags = appendDiagnostics(diags, importlint.DiagnosticsOf(err))
+	return g, diags
+}
+
+func writeGraph(w io.Writer, g *importlint.Graph, conf *importlint.Config, format string) error {
+	switch format {
+	case "dot":
+		return writeDOT(w, g, conf)
+	case "mermaid":
+		return writeMermaid(w, g, conf)
+	case "graphml":
+		return writeGraphML(w, g, conf)
+	}
+	return errors.Errorf("unknown graph format %q", format)
+}
+
+// layerColors returns the map of the layer name to the fill color.
+func layerColors(conf *importlint.Config) map[string]string {
+	layers := make([]string, 0, len(conf.Layers))
+	for layer := range conf.Layers {
+		layers = append(layers, layer)
+	}
+	sort.Strings(layers)
+
+	colors := make(map[string]string, len(layers))
+	for i, layer := range layers {
+		colors[layer] = layerPalette[i%len(layerPalette)]
+	}
+	return colors
+}
+
+func nodeColor(colors map[string]string, n *importlint.Node) string {
+	if c, ok := colors[n.Layer]; ok {
+		return c
+	}
+	return noLayerColor
+}
+
+// violates reports whether the edge e of g is not allowed by the layers of conf.
+func violates(g *importlint.Graph, conf *importlint.Config, e *importlint.Edge) bool {
+	from, to := g.Nodes[e.From].Layer, g.Nodes[e.To].Layer
+	return from != "" && to != "" && !conf.CanImport(from, to)
+}
+
+// sortedNodes returns the nodes of g sorted by the import path.
+func sortedNodes(g *importlint.Graph) []*importlint.Node {
+	nodes := make([]*importlint.Node, 0, len(g.Nodes))
+	for _, n := range g.Nodes {
+		nodes = append(nodes, n)
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].ImportPath < nodes[j].ImportPath })
+	return nodes
+}
+
+func writeDOT(w io.Writer, g *importlint.Graph, conf *importlint.Config) error {
+	colors := layerColors(conf)
+	fmt.Fprintln(w, "digraph importlint {")
+	fmt.Fprintln(w, "\tnode [shape=box, style=filled];")
+	for _, n := range sortedNodes(g) {
+		fmt.Fprintf(w, "\t%q [fillcolor=%q, tooltip=%q];\n", n.ImportPath, nodeColor(colors, n), n.Layer)
+	}
+	for _, e := range g.Edges() {
+		attr := ""
+		if violates(g, conf, e) {
+			attr = " [color=red, penwidth=2]"
+		}
+		fmt.Fprintf(w, "\t%q -> %q%s;\n", e.From, e.To, attr)
+	}
+	_, err := fmt.Fprintln(w, "}")
+	return err
+}
+
+func writeMermaid(w io.Writer, g *importlint.Graph, conf *importlint.Config) error {
+	colors := layerColors(conf)
+	ids := make(map[string]string, len(g.Nodes))
+	fmt.Fprintln(w, "graph LR")
+	for i, n := range sortedNodes(g) {
+		id := fmt.Sprintf("n%d", i)
+		ids[n.ImportPath] = id
+		fmt.Fprintf(w, "\t%s[\"%s\"]\n", id, strings.Replace(n.ImportPath, `"`, "#quot;", -1))
+		fmt.Fprintf(w, "\tstyle %s fill:%s\n", id, nodeColor(colors, n))
+	}
+	for i, e := range g.Edges() {
+		fmt.Fprintf(w, "\t%s --> %s\n", ids[e.From], ids[e.To])
+		if violates(g, conf, e) {
+			fmt.Fprintf(w, "\tlinkStyle %d stroke:red,stroke-width:2px\n", i)
+		}
+	}
+	return nil
+}
+
+// writeGraphML writes the graph in GraphML with the yFiles extensions, which yEd
+// uses to render the colors and labels.
+func writeGraphML(w io.Writer, g *importlint.Graph, conf *importlint.Config) error {
+	colors := layerColors(conf)
+	esc := func(s string) string {
+		var b strings.Builder
+		xml.EscapeText(&b, []byte(s))
+		return b.String()
+	}
+
+	fmt.Fprintln(w, `<?xml version="1.0" encoding="UTF-8"?>`)
+	fmt.Fprintln(w, `<graphml xmlns="http://graphml.graphdrawing.org/xmlns" xmlns:y="http://www.yworks.com/xml/graphml">`)
+	fmt.Fprintln(w, `  <key id="layer" for="node" attr.name="layer" attr.type="string"/>`)
+	fmt.Fprintln(w, `  <key id="class" for="node" attr.name="class" attr.type="string"/>`)
+	fmt.Fprintln(w, `  <key id="imports" for="edge" attr.name="imports" attr.type="int"/>`)
+	fmt.Fprintln(w, `  <key id="violation" for="edge" attr.name="violation" attr.type="boolean"/>`)
+	fmt.Fprintln(w, `  <key id="ngraphics" for="node" yfiles.type="nodegraphics"/>`)
+	fmt.Fprintln(w, `  <key id="egraphics" for="edge" yfiles.type="edgegraphics"/>`)
+	fmt.Fprintln(w, `  <graph id="importlint" edgedefault="directed">`)
+	for _, n := range sortedNodes(g) {
+		fmt.Fprintf(w, "    <node id=\"%s\">\n", esc(n.ImportPath))
+		fmt.Fprintf(w, "      <data key=\"layer\">%s</data>\n", esc(n.Layer))
+		fmt.Fprintf(w, "      <data key=\"class\">%s</data>\n", n.Class)
+		fmt.Fprintf(w, "      <data key=\"ngraphics\"><y:ShapeNode><y:Fill color=\"%s\"/><y:NodeLabel>%s</y:NodeLabel></y:ShapeNode></data>\n", nodeColor(colors, n), esc(n.ImportPath))
+		fmt.Fprintln(w, "    </node>")
+	}
+	for i, e := range g.Edges() {
+		color, violation := "#000000", violates(g, conf, e)
+		if violation {
+			color = "#ff0000"
+		}
+		fmt.Fprintf(w, "    <edge id=\"e%d\" source=\"%s\" target=\"%s\">\n", i, esc(e.From), esc(e.To))
+		fmt.Fprintf(w, "      <data key=\"imports\">%d</data>\n", len(e.Positions))
+		fmt.Fprintf(w, "      <data key=\"violation\">%t</data>\n", violation)
+		fmt.Fprintf(w, "      <data key=\"egraphics\"><y:PolyLineEdge><y:LineStyle color=\"%s\"/><y:Arrows source=\"none\" target=\"standard\"/></y:PolyLineEdge></data>\n", color)
+		fmt.Fprintln(w, "    </edge>")
+	}
+	fmt.Fprintln(w, "  </graph>")
+	_, err := fmt.Fprintln(w, "</graphml>")
+	return err
+}
@@ -0,0 +1,129 @@
+// Copyright 2017 The go-importlint Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	_ "embed" // for reportHTML
+	"encoding/json"
+	"html/template"
+	"io"
+	"path/filepath"
+
+	importlint "github.com/zchee/go-importlint"
+)
+
+// reportHTML is the template of the html format. It has no external assets, so the
+// report works offline.
+//
+//go:embed report.html
+var reportHTML string
+
+var reportTemplate = template.Must(template.New("report").Parse(reportHTML))
+
+type htmlPackage struct {
+	ImportPath string `json:"importPath"`
+	Dir        string `json:"dir"`
+	Layer      string `json:"layer"`
+	Violations []int  `json:"violations"` // indexes of htmlReport.Violations
+}
+
+type htmlEdge struct {
+	From      string `json:"from"`
+	To        string `json:"to"`
+	Imports   int    `json:"imports"`
+	Violation bool   `json:"violation"`
+}
+
+type htmlLayer struct {
+	Name       string `json:"name"`
+	Color      string `json:"color"`
+	Packages   int    `json:"packages"`
+	Violations int    `json:"violations"`
+	Imports    int    `json:"imports"`   // imports of the other layers
+	Importers  int    `json:"importers"` // imports from the other layers
+}
+
+type htmlReport struct {
+	Layers      []htmlLayer      `json:"layers"`
+	Packages    []htmlPackage    `json:"packages"`
+	Edges       []htmlEdge       `json:"edges"`
+	Violations  []jsonViolation  `json:"violations"`
+	Cycles      []jsonCycle      `json:"cycles"`
+	Diagnostics []jsonDiagnostic `json:"diagnostics"`
+}
+
+// writeHTML writes the single HTML file which has the violations, the summary of
+// each layer and the interactive graph of the project packages.
+func (r *report) writeHTML(w io.Writer) error {
+	jr := r.jsonReport()
+	out := htmlReport{
+		Layers:      []htmlLayer{},
+		Packages:    []htmlPackage{},
+		Edges:       []htmlEdge{},
+		Violations:  jr.Violations,
+		Cycles:      jr.Cycles,
+		Diagnostics: jr.Diagnostics,
+	}
+
+	conf := r.Config
+	if conf == nil {
+		conf = new(importlint.Config)
+	}
+	layers := make(map[string]*htmlLayer)
+	colors := layerColors(conf)
+	for _, name := range conf.LayerOrder() {
+		out.Layers = append(out.Layers, htmlLayer{Name: name, Color: colors[name]})
+	}
+	for i := range out.Layers {
+		layers[out.Layers[i].Name] = &out.Layers[i]
+	}
+
+	byDir := make(map[string][]int)
+	for i, v := range r.Violations {
+		dir := filepath.Dir(v.Pos.Filename)
+		byDir[dir] = append(byDir[dir], i)
+		if l, ok := layers[v.Layer]; ok {
+			l.Violations++
+		}
+	}
+
+	if r.Graph != nil {
+		g := r.Graph.Aggregate(func(n *importlint.Node) string {
+			if n.Class != importlint.ProjectImport {
+				return ""
+			}
+			return n.ImportPath
+		})
+		for _, n := range sortedNodes(g) {
+			vs := byDir[n.Dir]
+			if vs == nil {
+				vs = []int{}
+			}
+			out.Packages = append(out.Packages, htmlPackage{ImportPath: n.ImportPath, Dir: n.Dir, Layer: n.Layer, Violations: vs})
+			if l, ok := layers[n.Layer]; ok {
+				l.Packages++
+			}
+		}
+		for _, e := range g.Edges() {
+			out.Edges = append(out.Edges, htmlEdge{From: e.From, To: e.To, Imports: len(e.Positions), Violation: violates(g, conf, e)})
+			from, to := g.Nodes[e.From].Layer, g.Nodes[e.To].Layer
+			if from == to {
+				continue
+			}
+			if l, ok := layers[from]; ok {
+				l.Imports++
+			}
+			if l, ok := layers[to]; ok {
+				l.Importers++
+			}
+		}
+	}
+
+	data, err := json.Marshal(out)
+	if err != nil {
+		return err
+	}
+	return reportTemplate.Execute(w, string(data))
+}
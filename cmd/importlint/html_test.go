@@ -0,0 +1,109 @@
+// Copyright 2017 The go-importlint Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	importlint "github.com/zchee/go-importlint"
+)
+
+var reportDataRe = regexp.MustCompile(`const data = JSON\.parse\((".*")\);`)
+
+func TestWriteHTML(t *testing.T) {
+	gopath, err := filepath.Abs(filepath.Join("..", "..", "testdata", "graph"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := filepath.Join(gopath, "src", "shop")
+	conf := &importlint.Config{
+		Layers: map[string]importlint.LayerConfig{
+			"domain":         {},
+			"application":    {Allow: []string{"domain"}},
+			"infrastructure": {Allow: []string{"domain"}},
+		},
+	}
+	bc := importlint.NewBuildContext(root, importlint.WithGOROOT(""), importlint.WithGOPATH(gopath))
+	pkgs, err := bc.FindAllPackage(nil, importlint.ExcludeVendor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := importlint.NewGraph(token.NewFileSet(), &bc, pkgs, conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rep := &report{
+		Violations: []importlint.Violation{{
+			Pos:         token.Position{Filename: filepath.Join(root, "infrastructure", "infrastructure.go"), Line: 3, Column: 8},
+			Layer:       "infrastructure",
+			Import:      "shop/application",
+			ImportLayer: "application",
+		}},
+		Diagnostics: importlint.Diagnostics{{Kind: importlint.ParseError, Message: "</script><script>alert(1)</script>"}},
+		Graph:       g,
+		Config:      conf,
+	}
+	var buf bytes.Buffer
+	if err := rep.write(&buf, "html"); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if strings.Contains(out, "<script>alert(1)") {
+		t.Error("writeHTML() does not escape the report data in the script")
+	}
+
+	m := reportDataRe.FindStringSubmatch(out)
+	if m == nil {
+		t.Fatal("writeHTML() has no report data")
+	}
+	var data string
+	if err := json.Unmarshal([]byte(m[1]), &data); err != nil {
+		t.Fatalf("could not unquote the report data: %v", err)
+	}
+	var got htmlReport
+	if err := json.Unmarshal([]byte(data), &got); err != nil {
+		t.Fatalf("could not decode the report data: %v", err)
+	}
+
+	var layers []string
+	for _, l := range got.Layers {
+		layers = append(layers, l.Name)
+		if l.Name == "infrastructure" && (l.Packages != 1 || l.Violations != 1 || l.Imports != 1) {
+			t.Errorf("infrastructure layer = %+v, want 1 package, 1 violation and 1 import", l)
+		}
+	}
+	if want := []string{"domain", "application", "infrastructure"}; !reflect.DeepEqual(layers, want) {
+		t.Errorf("layers = %v, want %v", layers, want)
+	}
+	for _, p := range got.Packages {
+		want := []int{}
+		if p.ImportPath == "shop/infrastructure" {
+			want = []int{0}
+		}
+		if !reflect.DeepEqual(p.Violations, want) {
+			t.Errorf("violations of %s = %v, want %v", p.ImportPath, p.Violations, want)
+		}
+	}
+	var violating []string
+	for _, e := range got.Edges {
+		if e.Violation {
+			violating = append(violating, e.From+" -> "+e.To)
+		}
+	}
+	if want := []string{"shop/infrastructure -> shop/application"}; !reflect.DeepEqual(violating, want) {
+		t.Errorf("violating edges = %v, want %v", violating, want)
+	}
+	if len(got.Diagnostics) != 1 || got.Diagnostics[0].Message != "</script><script>alert(1)</script>" {
+		t.Errorf("diagnostics = %+v, want the parse error", got.Diagnostics)
+	}
+}
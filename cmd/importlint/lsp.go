@@ -0,0 +1,29 @@
+// Copyright 2017 The go-importlint Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+
+	"github.com/pkg/errors"
+	importlint "github.com/zchee/go-importlint"
+	"github.com/zchee/go-importlint/lsp"
+)
+
+// runLSP runs the Language Server Protocol server over stdio.
+func runLSP(args []string) {
+	fs := flag.NewFlagSet("lsp", flag.ExitOnError)
+	configFlag(fs)
+	fs.Parse(args)
+
+	conf, confPath := loadConfig(*config, ".")
+	resolver := importlint.NewConfigResolver(".", confPath, conf)
+
+	if err := lsp.NewServer(resolver).Serve(os.Stdin, os.Stdout); err != nil {
+		log.Fatal(errors.Wrap(err, "lsp server"))
+	}
+}
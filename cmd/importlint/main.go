@@ -5,23 +5,73 @@
 package main
 
 import (
-	"bytes"
 	"flag"
 	"fmt"
+	"go/build"
+	"go/parser"
+	"go/token"
 	"log"
 	"os"
+	"strings"
 
-	"github.com/davecgh/go-spew/spew"
 	"github.com/pkg/errors"
 	importlint "github.com/zchee/go-importlint"
+	"golang.org/x/tools/go/buildutil"
 )
 
+var (
+	config   = flag.String("config", "", "config file `path` (default: search .importlint.yaml upward from the directory)")
+	verbose  = flag.Bool("v", false, "verbose output")
+	format   = flag.String("format", "text", "output `format`: text, json or html")
+	modified = flag.Bool("modified", false, "read an archive of modified files from standard input")
+	goos     = flag.String("goos", build.Default.GOOS, "target `GOOS` of the build constraints")
+	goarch   = flag.String("goarch", build.Default.GOARCH, "target `GOARCH` of the build constraints")
+	allpath  = flag.Bool("gopath", false, "lint the packages in all GOPATH entries instead of the directory")
+	shadow   = flag.Bool("shadow", false, "report the import paths which exist in multiple GOPATH entries or vendor directories")
+	matrix   = flag.String("matrix", "", "comma separated list of `GOOS/GOARCH` to lint each platform and merge the results")
+	tags     []string
+)
+
+// The exit codes. Those are combined if both are found.
+const (
+	exitViolation  = 1 << 0 // found the layer violations or the component cycles
+	exitDiagnostic = 1 << 1 // could not load or parse some packages, or resolve some imports
+)
+
+func init() {
+	flag.Var((*buildutil.TagsFlag)(&tags), "tags", buildutil.TagsFlagDoc)
+}
+
 func main() {
 	flag.Parse()
 
+	switch flag.Arg(0) {
+	case "lsp":
+		runLSP(flag.Args()[1:])
+		return
+	case "vendor":
+		runVendor(flag.Args()[1:])
+		return
+	case "config":
+		runConfig(flag.Args()[1:])
+		return
+	case "graph":
+		runGraph(flag.Args()[1:])
+		return
+	case "dsm":
+		runDSM(flag.Args()[1:])
+		return
+	case "metrics":
+		runMetrics(flag.Args()[1:])
+		return
+	case "depth":
+		runDepth(flag.Args()[1:])
+		return
+	}
+
 	var path string
 	if flag.NArg() > 0 {
-		path = flag.Arg(0)
+		path = targetDir(flag.Arg(0))
 	} else {
 		wd, err := os.Getwd()
 		if err != nil {
@@ -30,20 +80,182 @@ func main() {
 		path = wd
 	}
 
+	conf, confPath := loadConfig(*config, path)
+	resolver := importlint.NewConfigResolver(path, confPath, conf)
+
 	bc := importlint.NewBuildContext(path)
-	pkgs, err := bc.FindAllPackage(nil, importlint.ExcludeVendor)
+	if *modified {
+		// The archive format is the same as guru's -modified flag.
+		overlay, err := buildutil.ParseOverlayArchive(os.Stdin)
+		if err != nil {
+			log.Fatal(errors.Wrap(err, "could not parse modified archive"))
+		}
+		bc = bc.WithOverlay(overlay)
+	}
+
+	if *shadow {
+		reportShadows(&bc)
+	}
+
+	var rep *report
+	if *matrix != "" {
+		rep = new(report)
+		byPlatform := make(map[string][]importlint.Violation)
+		for _, platform := range strings.Split(*matrix, ",") {
+			platform = strings.TrimSpace(platform)
+			i := strings.IndexByte(platform, '/')
+			if i < 0 {
+				log.Fatalf("invalid -matrix platform %q: want GOOS/GOARCH", platform)
+			}
+			pbc := bc.WithPlatform(platform[:i], platform[i+1:], tags)
+			prep := lint(&pbc, resolver)
+			byPlatform[platform] = prep.Violations
+			rep.Diagnostics = appendDiagnostics(rep.Diagnostics, prep.Diagnostics)
+			rep.Packages = appendPackages(rep.Packages, prep.Packages)
+		}
+		rep.Violations = importlint.MergeViolations(byPlatform)
+	} else {
+		pbc := bc.WithPlatform(*goos, *goarch, tags)
+		rep = lint(&pbc, resolver)
+	}
+
+	if len(conf.Components) > 0 || *format == "html" {
+		pbc := bc.WithPlatform(*goos, *goarch, tags)
+		g, _ := newGraph(&pbc, conf) // diagnostics are reported by lint
+		// The root config is enough since the nested configs can not change the components.
+		rep.Cycles = g.ComponentCycles(conf)
+		if *format == "html" {
+			rep.Graph, rep.Config = g, conf
+		}
+	}
+
+	if err := rep.write(os.Stdout, *format); err != nil {
+		log.Fatal(err)
+	}
+	os.Exit(exitCode(rep))
+}
+
+// loadConfig parses the config file of path, or the config found from dir if path is empty.
+// Returns the config and the path of that.
+func loadConfig(path, dir string) (*importlint.Config, string) {
+	if path == "" {
+		found, err := importlint.FindConfig(dir)
+		if err != nil {
+			log.Fatal(errors.Wrapf(err, "could not find config from %s", dir))
+		}
+		path = found
+	}
+	if *verbose {
+		log.Printf("using config %s", path)
+	}
+
+	conf, err := importlint.ParseConfig(path)
 	if err != nil {
-		log.Fatal(errors.Wrapf(err, "could not find packages on %s", path))
+		log.Fatal(errors.Wrap(err, "could not parse config"))
+	}
+	return conf, path
+}
+
+func exitCode(rep *report) int {
+	return exitStatus(len(rep.Violations) > 0 || len(rep.Cycles) > 0, len(rep.Diagnostics) > 0)
+}
+
+// exitStatus returns the exit code combining exitViolation and exitDiagnostic.
+func exitStatus(violations, diagnostics bool) int {
+	code := 0
+	if violations {
+		code |= exitViolation
+	}
+	if diagnostics {
+		code |= exitDiagnostic
+	}
+	return code
+}
+
+// configFlag defines the -config flag of the subcommand, which shares the value
+// with the global one.
+func configFlag(fs *flag.FlagSet) {
+	fs.StringVar(config, "config", *config, "config file `path` (default: search .importlint.yaml upward from the directory)")
+}
+
+// pathArg returns the directory argument of the subcommand, or "." if omitted.
+func pathArg(fs *flag.FlagSet) string {
+	if fs.NArg() > 0 {
+		return targetDir(fs.Arg(0))
+	}
+	return "."
+}
+
+// targetDir returns the directory of the path argument. The trailing "/..." such as
+// "./..." is trimmed, since all packages beneath the directory are linted anyway.
+func targetDir(arg string) string {
+	if arg == "..." {
+		return "."
+	}
+	if dir := strings.TrimSuffix(arg, "/..."); dir != arg {
+		if dir == "" {
+			return "/"
+		}
+		return dir
 	}
+	return arg
+}
 
-	buf := new(bytes.Buffer)
+// appendDiagnostics appends the diagnostics of ds which not in diags.
+func appendDiagnostics(diags, ds importlint.Diagnostics) importlint.Diagnostics {
+	seen := make(map[string]bool, len(diags))
+	for _, d := range diags {
+		seen[d.String()] = true
+	}
+	for _, d := range ds {
+		if !seen[d.String()] {
+			seen[d.String()] = true
+			diags = append(diags, d)
+		}
+	}
+	return diags
+}
+
+// lint checks all packages found in bc by the config resolved for each package.
+// The problems of loading and parsing the packages are reported as diagnostics.
+func lint(bc *importlint.BuildContext, resolver *importlint.ConfigResolver) *report {
+	pkgs, err := bc.FindAllPackage(nil, findMode(importlint.ExcludeVendor))
+	rep := &report{
+		Diagnostics: importlint.DiagnosticsOf(err),
+	}
+
+	fset := token.NewFileSet()
 	for _, pkg := range pkgs {
-		if !pkg.IsCommand() {
-			buf.WriteString(pkg.Dir + "\n")
+		conf, confPath, err := resolver.Resolve(pkg.Dir)
+		if err != nil {
+			log.Fatal(errors.Wrapf(err, "could not resolve config of %s", pkg.Dir))
 		}
+		if *verbose {
+			log.Printf("%s: using config %s", pkg.ImportPath, confPath)
+		}
+		rep.Packages = append(rep.Packages, packageReport{ImportPath: pkg.ImportPath, Dir: pkg.Dir, Config: confPath})
+
+		astpkgs, err := importlint.ParseDir(fset, bc, pkg.Dir, importlint.GoFilesFilter(pkg, true), parser.ImportsOnly)
+		rep.Diagnostics = appendDiagnostics(rep.Diagnostics, importlint.DiagnosticsOf(err))
+		violations, err := importlint.CheckDependency(fset, bc, astpkgs, conf)
+		rep.Diagnostics = appendDiagnostics(rep.Diagnostics, importlint.DiagnosticsOf(err))
+		rep.Violations = append(rep.Violations, violations...)
 	}
 
-	fmt.Print(buf.String())
+	return rep
+}
 
-	spew.Dump(bc.Context())
+// reportShadows prints the shadowed import paths to stderr.
+func reportShadows(bc *importlint.BuildContext) {
+	pkgs, _ := bc.FindAllPackage(nil, findMode(0)) // diagnostics are reported by lint
+	for _, s := range bc.FindShadows(pkgs) {
+		fmt.Fprintln(os.Stderr, s)
+	}
+}
+
+func findMode(mode importlint.FindMode) importlint.FindMode {
+	if *allpath {
+		mode |= importlint.AllGOPATH
+	}
+	return mode
 }
@@ -0,0 +1,26 @@
+// Copyright 2017 The go-importlint Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import "testing"
+
+func TestTargetDir(t *testing.T) {
+	tests := []struct {
+		arg  string
+		want string
+	}{
+		{".", "."},
+		{"./...", "."},
+		{"...", "."},
+		{"/tmp/proj/...", "/tmp/proj"},
+		{"proj/sub", "proj/sub"},
+		{"/...", "/"},
+	}
+	for _, tt := range tests {
+		if got := targetDir(tt.arg); got != tt.want {
+			t.Errorf("targetDir(%q) = %q, want %q", tt.arg, got, tt.want)
+		}
+	}
+}
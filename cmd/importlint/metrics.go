@@ -0,0 +1,203 @@
+// Copyright 2017 The go-importlint Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/token"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+
+	importlint "github.com/zchee/go-importlint"
+)
+
+// metricViolation is the metric which exceeds the threshold.
+type metricViolation struct {
+	Name      string  `json:"name"`
+	Metric    string  `json:"metric"`
+	Value     float64 `json:"value"`
+	Threshold float64 `json:"threshold"`
+}
+
+func (v metricViolation) String() string {
+	return fmt.Sprintf("%s: %s %.3g exceeds %.3g", v.Name, v.Metric, v.Value, v.Threshold)
+}
+
+// undefinedMetric is printed in the text format for the undefined instability
+// and distance of the groups without couplings.
+const undefinedMetric = "-"
+
+// jsonMetrics is the JSON output of Metrics. Instability and Distance are null
+// if the instability is undefined.
+type jsonMetrics struct {
+	Name         string   `json:"name"`
+	Packages     int      `json:"packages"`
+	Afferent     int      `json:"afferent"`
+	Efferent     int      `json:"efferent"`
+	Types        int      `json:"types"`
+	Interfaces   int      `json:"interfaces"`
+	Instability  *float64 `json:"instability"`
+	Abstractness float64  `json:"abstractness"`
+	Distance     *float64 `json:"distance"`
+}
+
+// runMetrics prints the coupling metrics of the packages or layers.
+func runMetrics(args []string) {
+	fs := flag.NewFlagSet("metrics", flag.ExitOnError)
+	configFlag(fs)
+	format := fs.String("format", "text", "output `format`: text, json or csv")
+	level := fs.String("level", "package", "metrics `level`: package or layer")
+	maxDistance := fs.Float64("max-distance", 0, "report the distance from the main sequence greater than `d` (0 disables)")
+	maxInstability := fs.Float64("max-instability", 0, "report the instability greater than `i` (0 disables)")
+	maxEfferent := fs.Int("max-efferent", 0, "report the efferent coupling greater than `n` (0 disables)")
+	fs.Parse(args)
+
+	path := pathArg(fs)
+
+	conf, _ := loadConfig(*config, path)
+	bc := importlint.NewBuildContext(path)
+	bc = bc.WithPlatform(*goos, *goarch, tags)
+	g, diags := newGraph(&bc, conf)
+
+	var key func(*importlint.Node) string
+	switch *level {
+	case "package":
+		key = func(n *importlint.Node) string { return n.ImportPath }
+	case "layer":
+		key = func(n *importlint.Node) string { return n.Layer }
+	default:
+		log.Fatalf("unknown metrics level %q", *level)
+	}
+	metrics, err := bc.Metrics(token.NewFileSet(), g, key)
+	diags = appendDiagnostics(diags, importlint.DiagnosticsOf(err))
+	for _, d := range diags {
+		fmt.Fprintln(os.Stderr, d)
+	}
+
+	var violations []metricViolation
+	for _, m := range metrics {
+		// The groups without couplings have no instability nor distance to check.
+		if d, ok := m.Distance(); ok && *maxDistance > 0 && d > *maxDistance {
+			violations = append(violations, metricViolation{Name: m.Name, Metric: "distance", Value: d, Threshold: *maxDistance})
+		}
+		if i, ok := m.Instability(); ok && *maxInstability > 0 && i > *maxInstability {
+			violations = append(violations, metricViolation{Name: m.Name, Metric: "instability", Value: i, Threshold: *maxInstability})
+		}
+		if *maxEfferent > 0 && m.Efferent > *maxEfferent {
+			violations = append(violations, metricViolation{Name: m.Name, Metric: "efferent", Value: float64(m.Efferent), Threshold: float64(*maxEfferent)})
+		}
+	}
+
+	if *format == "csv" {
+		for _, v := range violations {
+			fmt.Fprintln(os.Stderr, v)
+		}
+	}
+	w := bufio.NewWriter(os.Stdout)
+	if err := writeMetrics(w, metrics, violations, *format); err != nil {
+		log.Fatal(err)
+	}
+	if err := w.Flush(); err != nil {
+		log.Fatal(err)
+	}
+
+	os.Exit(exitStatus(len(violations) > 0, len(diags) > 0))
+}
+
+func writeMetrics(w io.Writer, metrics []importlint.Metrics, violations []metricViolation, format string) error {
+	switch format {
+	case "text":
+		tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+		fmt.Fprintln(tw, "NAME\tPKGS\tCA\tCE\tI\tA\tD")
+		for _, m := range metrics {
+			i, d := undefinedMetric, undefinedMetric
+			if v, ok := m.Instability(); ok {
+				i = fmt.Sprintf("%.2f", v)
+			}
+			if v, ok := m.Distance(); ok {
+				d = fmt.Sprintf("%.2f", v)
+			}
+			fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%s\t%.2f\t%s\n", m.Name, m.Packages, m.Afferent, m.Efferent, i, m.Abstractness(), d)
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+		for _, v := range violations {
+			fmt.Fprintln(w, v)
+		}
+		return nil
+
+	case "json":
+		out := struct {
+			Metrics    []jsonMetrics     `json:"metrics"`
+			Violations []metricViolation `json:"violations"`
+		}{
+			Metrics:    []jsonMetrics{},
+			Violations: violations,
+		}
+		if out.Violations == nil {
+			out.Violations = []metricViolation{}
+		}
+		for _, m := range metrics {
+			jm := jsonMetrics{
+				Name:         m.Name,
+				Packages:     m.Packages,
+				Afferent:     m.Afferent,
+				Efferent:     m.Efferent,
+				Types:        m.Types,
+				Interfaces:   m.Interfaces,
+				Abstractness: m.Abstractness(),
+			}
+			if i, ok := m.Instability(); ok {
+				jm.Instability = &i
+			}
+			if d, ok := m.Distance(); ok {
+				jm.Distance = &d
+			}
+			out.Metrics = append(out.Metrics, jm)
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(out)
+
+	case "csv":
+		// The violations are not in the csv, but printed to stderr by runMetrics.
+		// The undefined instability and distance are the empty fields.
+		cw := csv.NewWriter(w)
+		cw.Write([]string{"name", "packages", "afferent", "efferent", "types", "interfaces", "instability", "abstractness", "distance"})
+		for _, m := range metrics {
+			var i, d string
+			if v, ok := m.Instability(); ok {
+				i = strconv.FormatFloat(v, 'f', 4, 64)
+			}
+			if v, ok := m.Distance(); ok {
+				d = strconv.FormatFloat(v, 'f', 4, 64)
+			}
+			cw.Write([]string{
+				m.Name,
+				strconv.Itoa(m.Packages),
+				strconv.Itoa(m.Afferent),
+				strconv.Itoa(m.Efferent),
+				strconv.Itoa(m.Types),
+				strconv.Itoa(m.Interfaces),
+				i,
+				strconv.FormatFloat(m.Abstractness(), 'f', 4, 64),
+				d,
+			})
+		}
+		cw.Flush()
+		return cw.Error()
+	}
+	return errors.Errorf("unknown metrics format %q", format)
+}
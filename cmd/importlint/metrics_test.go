@@ -0,0 +1,48 @@
+// Copyright 2017 The go-importlint Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	importlint "github.com/zchee/go-importlint"
+)
+
+func TestWriteMetricsUndefined(t *testing.T) {
+	metrics := []importlint.Metrics{
+		{Name: "a", Packages: 1, Efferent: 1},
+		{Name: "b", Packages: 1, Types: 1},
+	}
+	tests := []struct {
+		format string
+		want   []string
+	}{
+		{"csv", []string{
+			"a,1,0,1,0,0,1.0000,0.0000,0.0000\n",
+			"b,1,0,0,1,0,,0.0000,\n",
+		}},
+		{"json", []string{
+			`"instability": 1,`,
+			`"distance": 0`,
+			`"instability": null,`,
+			`"distance": null`,
+		}},
+		{"text", []string{"b     1     0   0   -     0.00  -\n"}},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		if err := writeMetrics(&buf, metrics, nil, tt.format); err != nil {
+			t.Errorf("%s: writeMetrics() error = %v", tt.format, err)
+			continue
+		}
+		for _, want := range tt.want {
+			if !strings.Contains(buf.String(), want) {
+				t.Errorf("%s: writeMetrics() =\n%s\nwant containing %q", tt.format, buf.String(), want)
+			}
+		}
+	}
+}
@@ -0,0 +1,181 @@
+// Copyright 2017 The go-importlint Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/token"
+	"io"
+
+	"github.com/pkg/errors"
+	importlint "github.com/zchee/go-importlint"
+)
+
+// report is the result of linting.
+type report struct {
+	Violations  []importlint.Violation
+	Diagnostics importlint.Diagnostics
+	Packages    []packageReport
+	// Cycles is the import cycles between the components of the root config.
+	Cycles []importlint.ComponentCycle
+
+	// Graph is the import graph of the packages, and Config is the config of the
+	// root directory. Those are set only for the html format.
+	Graph  *importlint.Graph
+	Config *importlint.Config
+}
+
+// packageReport represents the linted package and the config file which governs it.
+type packageReport struct {
+	ImportPath string `json:"importPath"`
+	Dir        string `json:"dir"`
+	Config     string `json:"config"`
+}
+
+// appendPackages appends the packages of ps which not in pkgs.
+func appendPackages(pkgs, ps []packageReport) []packageReport {
+	seen := make(map[string]bool, len(pkgs))
+	for _, p := range pkgs {
+		seen[p.Dir] = true
+	}
+	for _, p := range ps {
+		if !seen[p.Dir] {
+			seen[p.Dir] = true
+			pkgs = append(pkgs, p)
+		}
+	}
+	return pkgs
+}
+
+func (r *report) write(w io.Writer, format string) error {
+	switch format {
+	case "text":
+		return r.writeText(w)
+	case "json":
+		return r.writeJSON(w)
+	case "html":
+		return r.writeHTML(w)
+	}
+	return errors.Errorf("unknown output format %q", format)
+}
+
+func (r *report) writeText(w io.Writer) error {
+	for _, d := range r.Diagnostics {
+		if _, err := fmt.Fprintln(w, d); err != nil {
+			return err
+		}
+	}
+	for _, v := range r.Violations {
+		if _, err := fmt.Fprintln(w, v); err != nil {
+			return err
+		}
+	}
+	for _, c := range r.Cycles {
+		if _, err := fmt.Fprintln(w, c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type jsonPosition struct {
+	Filename string `json:"filename"`
+	Line     int    `json:"line,omitempty"`
+	Column   int    `json:"column,omitempty"`
+}
+
+func newJSONPosition(pos token.Position) jsonPosition {
+	return jsonPosition{Filename: pos.Filename, Line: pos.Line, Column: pos.Column}
+}
+
+type jsonViolation struct {
+	Pos         jsonPosition `json:"pos"`
+	Layer       string       `json:"layer"`
+	Import      string       `json:"import"`
+	ImportLayer string       `json:"importLayer"`
+	Class       string       `json:"class"`
+	Resolved    string       `json:"resolved,omitempty"`
+	Dir         string       `json:"dir,omitempty"`
+	Platforms   []string     `json:"platforms,omitempty"`
+	Message     string       `json:"message"`
+}
+
+type jsonDiagnostic struct {
+	Pos      jsonPosition `json:"pos"`
+	Kind     string       `json:"kind"`
+	Message  string       `json:"message"`
+	Searched []string     `json:"searched,omitempty"`
+}
+
+type jsonEdge struct {
+	From      string         `json:"from"`
+	To        string         `json:"to"`
+	Positions []jsonPosition `json:"positions"`
+}
+
+type jsonCycle struct {
+	Components []string   `json:"components"`
+	Edges      []jsonEdge `json:"edges"`
+}
+
+type jsonReport struct {
+	Violations  []jsonViolation  `json:"violations"`
+	Cycles      []jsonCycle      `json:"cycles"`
+	Diagnostics []jsonDiagnostic `json:"diagnostics"`
+	Packages    []packageReport  `json:"packages"`
+}
+
+func (r *report) writeJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r.jsonReport())
+}
+
+func (r *report) jsonReport() jsonReport {
+	out := jsonReport{
+		Violations:  []jsonViolation{},
+		Cycles:      []jsonCycle{},
+		Diagnostics: []jsonDiagnostic{},
+		Packages:    r.Packages,
+	}
+	if out.Packages == nil {
+		out.Packages = []packageReport{}
+	}
+	for _, v := range r.Violations {
+		out.Violations = append(out.Violations, jsonViolation{
+			Pos:         newJSONPosition(v.Pos),
+			Layer:       v.Layer,
+			Import:      v.Import,
+			ImportLayer: v.ImportLayer,
+			Class:       v.Class.String(),
+			Resolved:    v.Resolved,
+			Dir:         v.Dir,
+			Platforms:   v.Platforms,
+			Message:     v.Message(),
+		})
+	}
+	for _, c := range r.Cycles {
+		jc := jsonCycle{Components: c.Components, Edges: []jsonEdge{}}
+		for _, e := range c.Edges {
+			je := jsonEdge{From: e.From, To: e.To, Positions: []jsonPosition{}}
+			for _, pos := range e.Positions {
+				je.Positions = append(je.Positions, newJSONPosition(pos))
+			}
+			jc.Edges = append(jc.Edges, je)
+		}
+		out.Cycles = append(out.Cycles, jc)
+	}
+	for _, d := range r.Diagnostics {
+		out.Diagnostics = append(out.Diagnostics, jsonDiagnostic{
+			Pos:      newJSONPosition(d.Pos),
+			Kind:     d.Kind.String(),
+			Message:  d.Message,
+			Searched: d.Searched,
+		})
+	}
+
+	return out
+}
@@ -0,0 +1,91 @@
+// Copyright 2017 The go-importlint Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"go/token"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	importlint "github.com/zchee/go-importlint"
+)
+
+func TestWriteJSON(t *testing.T) {
+	rep := &report{
+		Violations: []importlint.Violation{
+			{
+				Pos:         token.Position{Filename: "domain/domain.go", Line: 3, Column: 8},
+				Layer:       "domain",
+				Import:      "app/infrastructure",
+				ImportLayer: "infrastructure",
+				Class:       importlint.ProjectImport,
+				Resolved:    "app/infrastructure",
+				Dir:         "/go/src/app/infrastructure",
+				Platforms:   []string{"linux/amd64", "windows/amd64"},
+			},
+			{
+				Pos:         token.Position{Filename: "domain/orm.go", Line: 4, Column: 2},
+				Layer:       "domain",
+				Import:      "github.com/x/orm",
+				ImportLayer: "orm",
+				Class:       importlint.ThirdPartyImport,
+				Resolved:    "app/vendor/github.com/x/orm",
+			},
+		},
+		Diagnostics: importlint.Diagnostics{
+			{
+				Kind:     importlint.UnresolvedImport,
+				Pos:      token.Position{Filename: "cmd/main.go", Line: 5, Column: 2},
+				Message:  `could not resolve import "github.com/x/missing"`,
+				Searched: []string{"/go/src/app/vendor/github.com/x/missing", "/go/src/github.com/x/missing"},
+			},
+		},
+		Packages: []packageReport{
+			{ImportPath: "app/domain", Dir: "/go/src/app/domain", Config: "/go/src/app/.importlint.yaml"},
+		},
+		Cycles: []importlint.ComponentCycle{
+			{
+				Components: []string{"billing", "payments"},
+				Edges: []*importlint.Edge{
+					{From: "app/billing", To: "app/payments", Positions: []token.Position{{Filename: "billing/billing.go", Line: 3, Column: 8}}},
+					{From: "app/payments", To: "app/billing"},
+				},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := rep.write(&buf, "json"); err != nil {
+		t.Fatal(err)
+	}
+	want, err := ioutil.ReadFile(filepath.Join("testdata", "report.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); got != string(want) {
+		t.Errorf("writeJSON() = %s, want %s", got, want)
+	}
+}
+
+func TestWriteJSONEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	if err := new(report).write(&buf, "json"); err != nil {
+		t.Fatal(err)
+	}
+
+	// the empty lists are encoded as the empty arrays instead of null.
+	var got map[string][]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"violations", "cycles", "diagnostics", "packages"} {
+		if list, ok := got[key]; !ok || list == nil || len(list) != 0 {
+			t.Errorf("writeJSON() %s = %v, want []", key, list)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+// Copyright 2017 The go-importlint Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	importlint "github.com/zchee/go-importlint"
+)
+
+// runVendor checks the consistency of the vendor directory with the lock file.
+func runVendor(args []string) {
+	fs := flag.NewFlagSet("vendor", flag.ExitOnError)
+	fs.Parse(args)
+
+	path := pathArg(fs)
+
+	bc := importlint.NewBuildContext(path)
+	pkgs, err := bc.FindAllPackage(nil, importlint.ExcludeVendor)
+	diags := importlint.DiagnosticsOf(err)
+
+	issues, err := bc.CheckVendor(pkgs)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, d := range diags {
+		fmt.Println(d)
+	}
+	for _, issue := range issues {
+		fmt.Println(issue)
+	}
+
+	os.Exit(exitStatus(len(issues) > 0, len(diags) > 0))
+}
@@ -0,0 +1,97 @@
+// Copyright 2017 The go-importlint Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package importlint
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// ComponentOf returns the component name of importPath. The component is the one
+// which has the longest Components pattern matching importPath.
+func (c *Config) ComponentOf(importPath string) (string, bool) {
+	var (
+		found   string
+		longest = -1
+	)
+	for comp, patterns := range c.Components {
+		for _, pattern := range patterns {
+			if c.Project != "" {
+				pattern = path.Join(c.Project, pattern)
+			}
+			if !matchPattern(pattern, importPath) {
+				continue
+			}
+			if len(pattern) > longest || len(pattern) == longest && comp < found {
+				found, longest = comp, len(pattern)
+			}
+		}
+	}
+	return found, longest >= 0
+}
+
+// ComponentCycle represents the components which import each other directly or indirectly.
+type ComponentCycle struct {
+	// Components is the sorted names of the components in the cycle.
+	Components []string
+	// Edges is the imports between the packages of the different components in the cycle.
+	Edges []*Edge
+}
+
+func (c ComponentCycle) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "import cycle between components %s", strings.Join(c.Components, ", "))
+	for _, e := range c.Edges {
+		if len(e.Positions) == 0 {
+			fmt.Fprintf(&b, "\n\t%s imports %s", e.From, e.To)
+			continue
+		}
+		fmt.Fprintf(&b, "\n\t%s: %s imports %s", e.Positions[0], e.From, e.To)
+	}
+	return b.String()
+}
+
+// ComponentCycles returns the cycles of the components of conf in the project packages
+// of g, which are the strongly connected components of the graph aggregated by the
+// components. The patterns are relative to g.Project if conf.Project is empty.
+func (g *Graph) ComponentCycles(conf *Config) []ComponentCycle {
+	if conf.Project == "" && g.Project != "" {
+		c := *conf
+		c.Project = g.Project
+		conf = &c
+	}
+
+	keys := make(map[string]string)
+	for _, n := range g.Nodes {
+		if n.Class != ProjectImport {
+			continue
+		}
+		if comp, ok := conf.ComponentOf(n.ImportPath); ok {
+			keys[n.ImportPath] = comp
+		}
+	}
+	ag := g.Aggregate(func(n *Node) string { return keys[n.ImportPath] })
+
+	var cycles []ComponentCycle
+	for _, scc := range ag.StronglyConnectedComponents() {
+		if len(scc) < 2 {
+			continue
+		}
+		inCycle := make(map[string]bool, len(scc))
+		for _, comp := range scc {
+			inCycle[comp] = true
+		}
+		cycle := ComponentCycle{Components: scc}
+		for _, e := range g.Edges() {
+			from, to := keys[e.From], keys[e.To]
+			if from != to && inCycle[from] && inCycle[to] {
+				cycle.Edges = append(cycle.Edges, e)
+			}
+		}
+		cycles = append(cycles, cycle)
+	}
+	return cycles
+}
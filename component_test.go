@@ -0,0 +1,93 @@
+// Copyright 2017 The go-importlint Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package importlint
+
+import (
+	"go/token"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestComponentCycles(t *testing.T) {
+	tests := []struct {
+		name  string
+		g     *Graph
+		comps map[string][]string
+		want  []string // the components of each cycle joined by ","
+	}{
+		{
+			name: "cycle",
+			g: newTestGraph(
+				[2]string{"app/billing", "app/payments/api"},
+				[2]string{"app/payments", "app/billing"},
+				[2]string{"app/payments", "app/shared"},
+			),
+			comps: map[string][]string{
+				"billing":  {"billing/..."},
+				"payments": {"payments/..."},
+				"shared":   {"shared/..."},
+			},
+			want: []string{"billing,payments"},
+		},
+		{
+			name: "acyclic",
+			g: newTestGraph(
+				[2]string{"app/billing", "app/shared"},
+				[2]string{"app/payments", "app/billing"},
+				[2]string{"app/payments", "app/shared"},
+			),
+			comps: map[string][]string{
+				"billing":  {"billing/..."},
+				"payments": {"payments/..."},
+				"shared":   {"shared/..."},
+			},
+		},
+		{
+			name: "in the same component",
+			g: newTestGraph(
+				[2]string{"app/billing", "app/billing/internal"},
+				[2]string{"app/billing/internal", "app/shared"},
+			),
+			comps: map[string][]string{"billing": {"billing/..."}},
+		},
+		{
+			// billing_test imports payments, which imports billing.
+			name:  "external test",
+			g:     newFixtureGraph(t),
+			comps: map[string][]string{"billing": {"billing/..."}, "payments": {"payments/..."}},
+		},
+	}
+	for _, tt := range tests {
+		if tt.g.Project == "" {
+			tt.g.Project = "app"
+		}
+		conf := &Config{Components: tt.comps}
+		var got []string
+		for _, c := range tt.g.ComponentCycles(conf) {
+			got = append(got, strings.Join(c.Components, ","))
+			if len(c.Edges) == 0 {
+				t.Errorf("%s: cycle %v has no edges", tt.name, c.Components)
+			}
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: ComponentCycles() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestComponentCycleString(t *testing.T) {
+	c := ComponentCycle{
+		Components: []string{"a", "b"},
+		Edges: []*Edge{
+			{From: "app/a", To: "app/b", Positions: []token.Position{{Filename: "a.go", Line: 3, Column: 8}}},
+			{From: "app/b", To: "app/a"},
+		},
+	}
+	want := "import cycle between components a, b\n\ta.go:3:8: app/a imports app/b\n\tapp/b imports app/a"
+	if got := c.String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
@@ -5,27 +5,390 @@
 package importlint
 
 import (
+	_ "embed" // for ConfigSchema
+	"encoding/json"
+	"go/build"
 	"io/ioutil"
+	"path/filepath"
 
-	"github.com/go-yaml/yaml"
+	"github.com/BurntSushi/toml"
 	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
 )
 
+// ConfigVersion is the latest version of the config schema.
+const ConfigVersion = 2
+
+// Config represents the importlint config.
 type Config struct {
-	Project string              `yaml:"project"`
-	Layer   map[string][]string `yaml:"layer"`
+	// Version is the version of the config schema. The config without version is version 1.
+	Version int `yaml:"version,omitempty" json:"version,omitempty" toml:"version,omitempty"`
+
+	// Extends is the path of the base config. The relative path is resolved from
+	// the directory of the config file.
+	Extends string `yaml:"extends,omitempty" json:"extends,omitempty" toml:"extends,omitempty"`
+	// Include is the list of config paths merged after Extends in order.
+	Include []string `yaml:"include,omitempty" json:"include,omitempty" toml:"include,omitempty"`
+
+	Project string                 `yaml:"project" json:"project" toml:"project"`
+	Layers  map[string]LayerConfig `yaml:"layers" json:"layers" toml:"layers"`
+
+	// Components is the map of component name to the import path patterns of the
+	// packages in the component. The pattern is relative to the Project, and "..."
+	// matches any string. The imports between the components must not be cyclic.
+	Components map[string][]string `yaml:"components,omitempty" json:"components,omitempty" toml:"components,omitempty"`
+
+	// Layer is the map of layer name to the allowed layers of version 1.
+	//
+	// Deprecated: Use Layers. Upgrade converts Layer into Layers.
+	Layer map[string][]string `yaml:"layer,omitempty" json:"layer,omitempty" toml:"layer,omitempty"`
+
+	// origins is the map of the entry key to the config file path which defines it.
+	origins map[string]string
 }
 
+// LayerConfig represents the config of a layer.
+type LayerConfig struct {
+	// Allow is the list of layers which the layer is allowed to import.
+	Allow []string `yaml:"allow,omitempty" json:"allow,omitempty" toml:"allow,omitempty"`
+	// Packages is the list of import path patterns of the packages in the layer.
+	// The pattern is relative to the Project, and "..." matches any string.
+	// The layer without Packages is matched by the package name or the import path element.
+	Packages []string `yaml:"packages,omitempty" json:"packages,omitempty" toml:"packages,omitempty"`
+	// MaxDepth is the maximum length of the longest import chain of the packages in the
+	// layer through the project packages. Zero means no limit.
+	MaxDepth int `yaml:"maxDepth,omitempty" json:"maxDepth,omitempty" toml:"maxDepth,omitempty"`
+}
+
+// OverrideMarker is the first element of the list which replaces the list of the base config
+// instead of appending to it.
+const OverrideMarker = "!override"
+
+// ConfigSchema is the JSON Schema of Config.
+//
+//go:embed importlint.schema.json
+var ConfigSchema []byte
+
+// ConfigFileNames is the list of config file names searched by FindConfig in order.
+var ConfigFileNames = []string{
+	".importlint.yaml",
+	".importlint.yml",
+	"importlint.toml",
+	".importlint.json",
+}
+
+// ErrNoConfig is returned by FindConfig if the config file is not found.
+var ErrNoConfig = errors.New("could not find the config file")
+
+// ParseConfig parses the config file of path, and resolves the Extends and Include configs.
+// The format is chosen by the file extension, ".toml" is TOML, ".json" is JSON and
+// the others are YAML.
+//
+// The configs are merged in order of Extends, Include and the config itself.
+// The Layers maps are merged, and the Allow lists of the same layer are appended unless
+// the list starts with OverrideMarker. The non-zero MaxDepth overrides the base one.
+// The Components maps are merged in the same way as the Packages lists.
 func ParseConfig(path string) (*Config, error) {
+	return parseConfig(path, make(map[string]bool))
+}
+
+func parseConfig(path string, visiting map[string]bool) (*Config, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not get absolute path of %s", path)
+	}
+	if visiting[abs] {
+		return nil, errors.Errorf("config %s is extended or included recursively", path)
+	}
+	visiting[abs] = true
+	defer delete(visiting, abs)
+
+	raw, err := decodeConfig(path)
+	if err != nil {
+		return nil, err
+	}
+
+	conf := &Config{
+		Extends: raw.Extends,
+		Include: raw.Include,
+		Version: ConfigVersion,
+		Layers:  make(map[string]LayerConfig),
+		origins: make(map[string]string),
+	}
+	dir := filepath.Dir(path)
+	bases := raw.Include
+	if raw.Extends != "" {
+		bases = append([]string{raw.Extends}, bases...)
+	}
+	for _, base := range bases {
+		if !filepath.IsAbs(base) {
+			base = filepath.Join(dir, base)
+		}
+		bconf, err := parseConfig(base, visiting)
+		if err != nil {
+			return nil, err
+		}
+		conf.merge(bconf)
+	}
+
+	raw.setOrigin(path)
+	conf.merge(raw)
+
+	return conf, nil
+}
+
+// decodeConfig decodes the config file of path without resolving Extends and Include.
+func decodeConfig(path string) (*Config, error) {
 	buf, err := ioutil.ReadFile(path)
 	if err != nil {
 		return nil, errors.Wrapf(err, "could not read %s file", path)
 	}
 
 	conf := new(Config)
-	if err := yaml.Unmarshal(buf, &conf); err != nil {
-		return nil, err
+	switch filepath.Ext(path) {
+	case ".toml":
+		err = toml.Unmarshal(buf, conf)
+	case ".json":
+		err = json.Unmarshal(buf, conf)
+	default:
+		err = yaml.Unmarshal(buf, conf)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not parse %s", path)
+	}
+	if err := conf.Upgrade(); err != nil {
+		return nil, errors.Wrapf(err, "could not upgrade %s", path)
 	}
 
 	return conf, nil
 }
+
+// Upgrade converts c of the old schema version into the latest version in place.
+func (c *Config) Upgrade() error {
+	if c.Version == 0 {
+		c.Version = 1
+		if c.Layer == nil && c.Layers != nil {
+			c.Version = ConfigVersion
+		}
+	}
+
+	switch c.Version {
+	case 1:
+		if c.Layers != nil {
+			return errors.New("layers is not allowed in version 1 config, use layer")
+		}
+		c.Layers = make(map[string]LayerConfig, len(c.Layer))
+		for layer, deps := range c.Layer {
+			c.Layers[layer] = LayerConfig{Allow: deps}
+		}
+		c.Layer = nil
+		c.Version = ConfigVersion
+	case ConfigVersion:
+		if c.Layer != nil {
+			return errors.Errorf("layer is not allowed in version %d config, use layers", ConfigVersion)
+		}
+	default:
+		return errors.Errorf("unsupported config version %d", c.Version)
+	}
+
+	if c.Layers == nil {
+		c.Layers = make(map[string]LayerConfig)
+	}
+	return nil
+}
+
+// Origin returns the config file path which defines the entry of key.
+// The key is "project", "layer.<layer>", "layer.<layer>.<allowed layer>",
+// "packages.<layer>.<pattern>", "maxDepth.<layer>", "component.<component>" or
+// "component.<component>.<pattern>".
+func (c *Config) Origin(key string) string {
+	return c.origins[key]
+}
+
+func (c *Config) setOrigin(path string) {
+	c.origins = make(map[string]string)
+	if c.Project != "" {
+		c.origins["project"] = path
+	}
+	for layer, lc := range c.Layers {
+		c.origins["layer."+layer] = path
+		for _, dep := range lc.Allow {
+			c.origins["layer."+layer+"."+dep] = path
+		}
+		for _, pkg := range lc.Packages {
+			c.origins["packages."+layer+"."+pkg] = path
+		}
+		if lc.MaxDepth != 0 {
+			c.origins["maxDepth."+layer] = path
+		}
+	}
+	for comp, patterns := range c.Components {
+		c.origins["component."+comp] = path
+		for _, pattern := range patterns {
+			c.origins["component."+comp+"."+pattern] = path
+		}
+	}
+}
+
+// clone returns a deep copy of c.
+func (c *Config) clone() *Config {
+	conf := *c
+	conf.Layers = make(map[string]LayerConfig, len(c.Layers))
+	for layer, lc := range c.Layers {
+		lc.Allow = append([]string(nil), lc.Allow...)
+		lc.Packages = append([]string(nil), lc.Packages...)
+		conf.Layers[layer] = lc
+	}
+	conf.Components = make(map[string][]string, len(c.Components))
+	for comp, patterns := range c.Components {
+		conf.Components[comp] = append([]string(nil), patterns...)
+	}
+	conf.origins = make(map[string]string, len(c.origins))
+	for k, v := range c.origins {
+		conf.origins[k] = v
+	}
+	return &conf
+}
+
+// merge merges other into c.
+func (c *Config) merge(other *Config) {
+	if other.Project != "" {
+		c.Project = other.Project
+		c.origins["project"] = other.origins["project"]
+	}
+
+	for layer, olc := range other.Layers {
+		key := "layer." + layer
+		deps := olc.Allow
+		lc, ok := c.Layers[layer]
+		cur := lc.Allow
+		if len(deps) > 0 && deps[0] == OverrideMarker {
+			deps = deps[1:]
+			for _, dep := range cur {
+				delete(c.origins, key+"."+dep)
+			}
+			cur = nil
+			ok = false
+		}
+		if !ok {
+			c.origins[key] = other.origins[key]
+		}
+		for _, dep := range deps {
+			if !containsString(cur, dep) {
+				cur = append(cur, dep)
+				c.origins[key+"."+dep] = other.origins[key+"."+dep]
+			}
+		}
+		lc.Allow = cur
+
+		pkgs := olc.Packages
+		if len(pkgs) > 0 && pkgs[0] == OverrideMarker {
+			pkgs = pkgs[1:]
+			for _, pkg := range lc.Packages {
+				delete(c.origins, "packages."+layer+"."+pkg)
+			}
+			lc.Packages = nil
+		}
+		for _, pkg := range pkgs {
+			if !containsString(lc.Packages, pkg) {
+				lc.Packages = append(lc.Packages, pkg)
+				c.origins["packages."+layer+"."+pkg] = other.origins["packages."+layer+"."+pkg]
+			}
+		}
+
+		if olc.MaxDepth != 0 {
+			lc.MaxDepth = olc.MaxDepth
+			c.origins["maxDepth."+layer] = other.origins["maxDepth."+layer]
+		}
+
+		c.Layers[layer] = lc
+	}
+
+	for comp, patterns := range other.Components {
+		if c.Components == nil {
+			c.Components = make(map[string][]string)
+		}
+		key := "component." + comp
+		cur, ok := c.Components[comp]
+		if len(patterns) > 0 && patterns[0] == OverrideMarker {
+			patterns = patterns[1:]
+			for _, pattern := range cur {
+				delete(c.origins, key+"."+pattern)
+			}
+			cur = nil
+			ok = false
+		}
+		if !ok {
+			c.origins[key] = other.origins[key]
+		}
+		for _, pattern := range patterns {
+			if !containsString(cur, pattern) {
+				cur = append(cur, pattern)
+				c.origins[key+"."+pattern] = other.origins[key+"."+pattern]
+			}
+		}
+		c.Components[comp] = cur
+	}
+}
+
+func containsString(list []string, s string) bool {
+	for _, e := range list {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
+
+// FindConfig searches the config file from dir upward, and returns the path of found config.
+// The search stops at the project root, which is the directory having go.mod, the gb
+// project root, the src directory of a GOPATH entry or the root of a VCS repository.
+func FindConfig(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", errors.Wrapf(err, "could not get absolute path of %s", dir)
+	}
+
+	gbroot, isgb := isGb(dir)
+	for {
+		for _, name := range ConfigFileNames {
+			path := filepath.Join(dir, name)
+			if !isNotExist(path) {
+				return path, nil
+			}
+		}
+
+		if isSearchRoot(dir) || (isgb && dir == gbroot) {
+			break
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+
+	return "", ErrNoConfig
+}
+
+// vcsDirs is the metadata directories of the VCS repository roots.
+var vcsDirs = []string{".git", ".hg", ".svn", ".bzr"}
+
+// isSearchRoot reports whether FindConfig stops at dir, which is the module root,
+// the src directory of a GOPATH entry or the root of a VCS repository.
+func isSearchRoot(dir string) bool {
+	if !isNotExist(filepath.Join(dir, "go.mod")) {
+		return true
+	}
+	for _, gopath := range filepath.SplitList(build.Default.GOPATH) {
+		if gopath != "" && dir == srcDir(filepath.Clean(gopath)) {
+			return true
+		}
+	}
+	for _, vcs := range vcsDirs {
+		if !isNotExist(filepath.Join(dir, vcs)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,252 @@
+// Copyright 2017 The go-importlint Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package importlint
+
+import (
+	"go/build"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseConfig(t *testing.T) {
+	dir := filepath.Join("testdata", "config")
+	base := filepath.Join(dir, "base.yaml")
+	child := filepath.Join(dir, "child.yaml")
+	extra := filepath.Join(dir, "extra.yaml")
+
+	tests := []struct {
+		path    string
+		want    *Config
+		origins map[string]string
+	}{
+		{
+			path: child,
+			want: &Config{
+				Version: ConfigVersion,
+				Extends: "base.yaml",
+				Include: []string{"extra.yaml"},
+				Project: "example.com/app",
+				Layers: map[string]LayerConfig{
+					// the packages are replaced by !override
+					"domain": {Packages: []string{"model/..."}},
+					// the allowed layers are appended without duplicates
+					"application": {Allow: []string{"domain", "infrastructure"}},
+					// the allowed layers are replaced by !override, and the zero
+					// maxDepth does not override the included one
+					"infrastructure": {Allow: []string{"domain"}, MaxDepth: 4},
+					// the layer only in the included config
+					"presentation": {Allow: []string{"application"}},
+				},
+				Components: map[string][]string{
+					"core":     {"domain/...", "shared/..."},
+					"adapters": {"adapter/..."},
+				},
+			},
+			origins: map[string]string{
+				"project":                               extra,
+				"layer.domain":                          base,
+				"layer.application":                     base,
+				"layer.application.domain":              base,
+				"layer.application.infrastructure":      child,
+				"layer.infrastructure":                  child,
+				"layer.infrastructure.domain":           child,
+				"layer.infrastructure.application":      "",
+				"layer.presentation":                    extra,
+				"layer.presentation.application":        extra,
+				"layer.presentation.not-allowed":        "",
+				"layer.not-defined":                     "",
+				"layer.application.not-defined-dep":     "",
+				"layer.infrastructure.infrastructure":   "",
+				"packages.domain.model/...":             child,
+				"packages.domain.domain/...":            "",
+				"maxDepth.infrastructure":               extra,
+				"maxDepth.application":                  "",
+				"component.core":                        base,
+				"component.core.domain/...":             base,
+				"component.core.shared/...":             child,
+				"component.adapters":                    child,
+				"component.adapters.adapter/...":        child,
+				"component.adapters.infrastructure/...": "",
+			},
+		},
+		{
+			path: filepath.Join(dir, "child.toml"),
+			want: &Config{
+				Version: ConfigVersion,
+				Extends: "base.yaml",
+				Project: "app",
+				Layers: map[string]LayerConfig{
+					"domain":         {Packages: []string{"domain/..."}},
+					"application":    {Allow: []string{"domain", "infrastructure"}, MaxDepth: 2},
+					"infrastructure": {Allow: []string{"domain", "application"}, MaxDepth: 3},
+				},
+				Components: map[string][]string{
+					"core":     {"domain/..."},
+					"adapters": {"infrastructure/..."},
+				},
+			},
+			origins: map[string]string{
+				"project":                          base,
+				"layer.application.infrastructure": filepath.Join(dir, "child.toml"),
+				"maxDepth.application":             filepath.Join(dir, "child.toml"),
+				"maxDepth.infrastructure":          base,
+				"packages.domain.domain/...":       base,
+			},
+		},
+		{
+			// the same config included twice is not recursive
+			path: filepath.Join(dir, "diamond.yaml"),
+			want: &Config{
+				Version: ConfigVersion,
+				Extends: "base.yaml",
+				Include: []string{"extra.yaml", "extra.yaml"},
+				Project: "example.com/app",
+				Layers: map[string]LayerConfig{
+					"domain":         {Packages: []string{"domain/..."}},
+					"application":    {Allow: []string{"domain"}},
+					"infrastructure": {Allow: []string{"domain", "application"}, MaxDepth: 4},
+					"presentation":   {Allow: []string{"application"}},
+				},
+				Components: map[string][]string{
+					"core":     {"domain/..."},
+					"adapters": {"infrastructure/..."},
+				},
+			},
+		},
+		{
+			path: filepath.Join(dir, "v1.yaml"),
+			want: &Config{
+				Version: ConfigVersion,
+				Project: "app",
+				Layers: map[string]LayerConfig{
+					"domain":      {},
+					"application": {Allow: []string{"domain"}},
+				},
+			},
+			origins: map[string]string{
+				"layer.application.domain": filepath.Join(dir, "v1.yaml"),
+			},
+		},
+	}
+	for _, tt := range tests {
+		got, err := ParseConfig(tt.path)
+		if err != nil {
+			t.Errorf("ParseConfig(%s) error = %v", tt.path, err)
+			continue
+		}
+		for key, want := range tt.origins {
+			if origin := got.Origin(key); origin != want {
+				t.Errorf("ParseConfig(%s).Origin(%q) = %q, want %q", tt.path, key, origin, want)
+			}
+		}
+		got.origins = nil
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseConfig(%s) = %+v, want %+v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestParseConfigError(t *testing.T) {
+	dir := filepath.Join("testdata", "config")
+	tests := []struct {
+		path string
+		want string
+	}{
+		{filepath.Join(dir, "cycle_a.yaml"), "extended or included recursively"},
+		{filepath.Join(dir, "self.yaml"), "extended or included recursively"},
+		{filepath.Join(dir, "not-exist.yaml"), "could not read"},
+	}
+	for _, tt := range tests {
+		_, err := ParseConfig(tt.path)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("ParseConfig(%s) error = %v, want %q", tt.path, err, tt.want)
+		}
+	}
+}
+
+func TestMergeOverrideOrigins(t *testing.T) {
+	base := &Config{
+		Layers: map[string]LayerConfig{
+			"a": {Allow: []string{"b", "c"}, Packages: []string{"a/..."}},
+		},
+		Components: map[string][]string{"x": {"x/..."}},
+	}
+	base.setOrigin("base.yaml")
+	other := &Config{
+		Layers: map[string]LayerConfig{
+			"a": {Allow: []string{OverrideMarker, "c"}, Packages: []string{OverrideMarker}},
+		},
+		Components: map[string][]string{"x": {OverrideMarker, "y/..."}},
+	}
+	other.setOrigin("other.yaml")
+
+	conf := base.clone()
+	conf.merge(other)
+
+	want := map[string]LayerConfig{"a": {Allow: []string{"c"}}}
+	if !reflect.DeepEqual(conf.Layers, want) {
+		t.Errorf("merged layers = %+v, want %+v", conf.Layers, want)
+	}
+	if want := []string{"y/..."}; !reflect.DeepEqual(conf.Components["x"], want) {
+		t.Errorf("merged components = %v, want %v", conf.Components["x"], want)
+	}
+	for key, want := range map[string]string{
+		"layer.a":   "other.yaml",
+		"layer.a.b": "",
+		"layer.a.c": "other.yaml",
+		// the packages and the component patterns replaced by !override
+		"packages.a.a/...":  "",
+		"component.x":       "other.yaml",
+		"component.x.x/...": "",
+		"component.x.y/...": "other.yaml",
+	} {
+		if origin := conf.Origin(key); origin != want {
+			t.Errorf("Origin(%q) = %q, want %q", key, origin, want)
+		}
+	}
+
+	// merge must not modify the base config through the shared slices.
+	if want := []string{"b", "c"}; !reflect.DeepEqual(base.Layers["a"].Allow, want) {
+		t.Errorf("base allow = %v, want %v", base.Layers["a"].Allow, want)
+	}
+	if origin := base.Origin("layer.a.b"); origin != "base.yaml" {
+		t.Errorf("base Origin(layer.a.b) = %q, want base.yaml", origin)
+	}
+}
+
+func TestFindConfig(t *testing.T) {
+	dir := filepath.Join("testdata", "findconfig")
+	gopath, err := filepath.Abs(filepath.Join(dir, "gopath"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func(old string) { build.Default.GOPATH = old }(build.Default.GOPATH)
+	build.Default.GOPATH = gopath
+
+	tests := []struct {
+		dir     string
+		want    string
+		wantErr error
+	}{
+		{dir: filepath.Join(dir, "found", "sub"), want: filepath.Join(dir, "found", ".importlint.yaml")},
+		// the search stops at the VCS root and the src directory of GOPATH.
+		{dir: filepath.Join(dir, "repo", "sub"), wantErr: ErrNoConfig},
+		{dir: filepath.Join(dir, "gopath", "src", "app"), wantErr: ErrNoConfig},
+	}
+	for _, tt := range tests {
+		got, err := FindConfig(tt.dir)
+		if err != tt.wantErr {
+			t.Errorf("FindConfig(%s) error = %v, want %v", tt.dir, err, tt.wantErr)
+			continue
+		}
+		if tt.want != "" {
+			if want, _ := filepath.Abs(tt.want); got != want {
+				t.Errorf("FindConfig(%s) = %s, want %s", tt.dir, got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,144 @@
+// Copyright 2017 The go-importlint Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package importlint
+
+import (
+	"fmt"
+	"go/build"
+	"go/scanner"
+	"go/token"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// DiagnosticKind is the kind of Diagnostic.
+type DiagnosticKind int
+
+const (
+	// WalkError is the error of walking the directory tree.
+	WalkError DiagnosticKind = iota
+	// ImportError is the error of loading the package.
+	ImportError
+	// MultiplePackages is the directory which has the multiple package names.
+	MultiplePackages
+	// ParseError is the syntax error of the Go file.
+	ParseError
+	// UnresolvedImport is the import which could not be resolved to any directory.
+	UnresolvedImport
+)
+
+func (k DiagnosticKind) String() string {
+	switch k {
+	case WalkError:
+		return "walk error"
+	case ImportError:
+		return "import error"
+	case MultiplePackages:
+		return "multiple packages"
+	case ParseError:
+		return "parse error"
+	case UnresolvedImport:
+		return "unresolved import"
+	}
+	return fmt.Sprintf("DiagnosticKind(%d)", int(k))
+}
+
+// Diagnostic represents the problem of loading or parsing the packages, or resolving the imports.
+// The linting continues on the rest of tree, but the result may be incomplete.
+type Diagnostic struct {
+	Kind    DiagnosticKind
+	Pos     token.Position
+	Message string
+
+	// Searched is the list of directories searched for the UnresolvedImport in order.
+	Searched []string
+}
+
+func (d Diagnostic) String() string {
+	if len(d.Searched) > 0 {
+		return fmt.Sprintf("%s: %s: %s (searched %s)", d.Pos, d.Kind, d.Message, strings.Join(d.Searched, ", "))
+	}
+	return fmt.Sprintf("%s: %s: %s", d.Pos, d.Kind, d.Message)
+}
+
+// Diagnostics is a list of Diagnostic.
+// It implements the error interface, the same as scanner.ErrorList.
+type Diagnostics []Diagnostic
+
+func (d Diagnostics) Error() string {
+	switch len(d) {
+	case 0:
+		return "no diagnostics"
+	case 1:
+		return d[0].String()
+	}
+	return fmt.Sprintf("%s (and %d more diagnostics)", d[0], len(d)-1)
+}
+
+// Err returns an error equivalent to d, or nil if d is empty.
+func (d Diagnostics) Err() error {
+	if len(d) == 0 {
+		return nil
+	}
+	return d
+}
+
+// Sort sorts d by position.
+func (d Diagnostics) Sort() {
+	sort.SliceStable(d, func(i, j int) bool {
+		pi, pj := d[i].Pos, d[j].Pos
+		if pi.Filename != pj.Filename {
+			return pi.Filename < pj.Filename
+		}
+		if pi.Line != pj.Line {
+			return pi.Line < pj.Line
+		}
+		return pi.Column < pj.Column
+	})
+}
+
+// DiagnosticsOf converts err returned by FindAllPackage, ParseDir or CheckDependency into Diagnostics.
+// The other errors are converted to one ImportError.
+func DiagnosticsOf(err error) Diagnostics {
+	if err == nil {
+		return nil
+	}
+	if d, ok := err.(Diagnostics); ok {
+		return d
+	}
+	return Diagnostics{{Kind: ImportError, Message: err.Error()}}
+}
+
+// importDiagnostics converts err returned by build.Context.ImportDir on dir into Diagnostics.
+func importDiagnostics(dir string, err error) Diagnostics {
+	switch err := err.(type) {
+	case *build.MultiplePackageError:
+		var d Diagnostics
+		for i, file := range err.Files {
+			d = append(d, Diagnostic{
+				Kind:    MultiplePackages,
+				Pos:     token.Position{Filename: filepath.Join(err.Dir, file)},
+				Message: fmt.Sprintf("found package %s in %s", err.Packages[i], err.Dir),
+			})
+		}
+		return d
+	case scanner.ErrorList:
+		return parseDiagnostics(err)
+	case scanner.Error:
+		return parseDiagnostics(scanner.ErrorList{&err})
+	case *scanner.Error:
+		return parseDiagnostics(scanner.ErrorList{err})
+	}
+	return Diagnostics{{Kind: ImportError, Pos: token.Position{Filename: dir}, Message: err.Error()}}
+}
+
+func parseDiagnostics(list scanner.ErrorList) Diagnostics {
+	d := make(Diagnostics, 0, len(list))
+	for _, e := range list {
+		d = append(d, Diagnostic{Kind: ParseError, Pos: e.Pos, Message: e.Msg})
+	}
+	return d
+}
@@ -5,13 +5,129 @@
 package importlint
 
 import (
+	"bufio"
+	"encoding/json"
 	"fmt"
+	"os"
 	"path/filepath"
 	"strings"
 
 	"github.com/pkg/errors"
 )
 
+// GbProject represents the metadata of gb project.
+type GbProject struct {
+	// Root is the project root directory which has the src directory.
+	Root string
+	// Manifest is the vendor/manifest file of gb-vendor. It's nil if not exists.
+	Manifest *GbManifest
+	// Depfile is the list of dependencies written in the depfile. It's nil if not exists.
+	Depfile []GbDepfileEntry
+}
+
+// GbManifest represents the vendor/manifest file of gb-vendor.
+type GbManifest struct {
+	Version      int            `json:"version"`
+	Dependencies []GbDependency `json:"dependencies"`
+}
+
+// GbDependency represents the vendored dependency in the GbManifest.
+type GbDependency struct {
+	Importpath string `json:"importpath"`
+	Repository string `json:"repository"`
+	VCS        string `json:"vcs,omitempty"`
+	Revision   string `json:"revision"`
+	Branch     string `json:"branch"`
+	Path       string `json:"path,omitempty"`
+}
+
+// GbDepfileEntry represents the line of the depfile.
+//
+//	repository github.com/pkg/profile tag=v1.1.0
+type GbDepfileEntry struct {
+	Repository string
+	Options    map[string]string
+}
+
+// GbProject returns the metadata of gb project.
+// Returns nil and no error if b is not the gb project.
+func (b *BuildContext) GbProject() (*GbProject, error) {
+	if !b.gb {
+		return nil, nil
+	}
+
+	p := &GbProject{Root: b.root}
+
+	manifest := filepath.Join(b.root, "vendor", "manifest")
+	if !isNotExist(manifest) {
+		m, err := parseGbManifest(manifest)
+		if err != nil {
+			return nil, err
+		}
+		p.Manifest = m
+	}
+
+	depfile := filepath.Join(b.root, "depfile")
+	if !isNotExist(depfile) {
+		entries, err := parseGbDepfile(depfile)
+		if err != nil {
+			return nil, err
+		}
+		p.Depfile = entries
+	}
+
+	return p, nil
+}
+
+func parseGbManifest(filename string) (*GbManifest, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not open %s", filename)
+	}
+	defer f.Close()
+
+	m := new(GbManifest)
+	if err := json.NewDecoder(f).Decode(m); err != nil {
+		return nil, errors.Wrapf(err, "could not decode %s", filename)
+	}
+	return m, nil
+}
+
+func parseGbDepfile(filename string) ([]GbDepfileEntry, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not open %s", filename)
+	}
+	defer f.Close()
+
+	var entries []GbDepfileEntry
+	sc := bufio.NewScanner(f)
+	for lineno := 1; sc.Scan(); lineno++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 2 || fields[0] != "repository" {
+			return nil, errors.Errorf("%s:%d: unknown depfile line %q", filename, lineno, line)
+		}
+		entry := GbDepfileEntry{
+			Repository: fields[1],
+			Options:    make(map[string]string),
+		}
+		for _, opt := range fields[2:] {
+			kv := strings.SplitN(opt, "=", 2)
+			if len(kv) != 2 {
+				return nil, errors.Errorf("%s:%d: invalid depfile option %q", filename, lineno, opt)
+			}
+			entry.Options[kv[0]] = kv[1]
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, sc.Err()
+}
+
 // isGb check the current buffer directory whether gb directory structure.
 // Return the gb project root path and boolean.
 func isGb(dir string) (string, bool) {
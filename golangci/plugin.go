@@ -0,0 +1,63 @@
+// Copyright 2017 The go-importlint Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package golangci provides the golangci-lint module plugin of importlint.
+//
+// The layer config is written inline in .golangci.yml:
+//
+//	linters-settings:
+//	  custom:
+//	    importlint:
+//	      type: module
+//	      settings:
+//	        version: 2
+//	        project: github.com/foo/bar
+//	        layers:
+//	          application:
+//	            allow:
+//	              - domain
+//	          domain: {}
+package golangci
+
+import (
+	"github.com/golangci/plugin-module-register/register"
+	"github.com/pkg/errors"
+	"golang.org/x/tools/go/analysis"
+
+	importlint "github.com/zchee/go-importlint"
+	"github.com/zchee/go-importlint/analyzer"
+)
+
+func init() {
+	register.Plugin("importlint", New)
+}
+
+type plugin struct {
+	conf importlint.Config
+}
+
+var _ register.LinterPlugin = (*plugin)(nil)
+
+// New returns the importlint LinterPlugin from settings of .golangci.yml.
+func New(settings interface{}) (register.LinterPlugin, error) {
+	conf, err := register.DecodeSettings[importlint.Config](settings)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not decode importlint settings")
+	}
+	if err := conf.Upgrade(); err != nil {
+		return nil, errors.Wrap(err, "could not upgrade importlint settings")
+	}
+
+	return &plugin{conf: conf}, nil
+}
+
+// BuildAnalyzers implements register.LinterPlugin.
+func (p *plugin) BuildAnalyzers() ([]*analysis.Analyzer, error) {
+	return []*analysis.Analyzer{analyzer.New(&p.conf)}, nil
+}
+
+// GetLoadMode implements register.LinterPlugin.
+func (p *plugin) GetLoadMode() string {
+	return register.LoadModeSyntax
+}
@@ -0,0 +1,421 @@
+// Copyright 2017 The go-importlint Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package importlint
+
+import (
+	"fmt"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"sort"
+	"strconv"
+)
+
+// Node represents the package in the Graph.
+type Node struct {
+	ImportPath string
+	// Name is the package name, or empty if the package is not parsed such as
+	// the imported packages out of the graph roots.
+	Name string
+	Dir  string
+	// Layer is the layer of the package, or empty if the package is not in any layers.
+	Layer string
+	Class ImportClass
+}
+
+// Edge represents the import from the From package to the To package.
+// Both are the resolved import paths.
+type Edge struct {
+	From string
+	To   string
+	// Positions is the list of the import spec positions, which may be multiple
+	// if the package imports To from the multiple files.
+	Positions []token.Position
+}
+
+// Graph is the import graph of the packages.
+type Graph struct {
+	// Project is the import path of the project which the graph is built on.
+	Project string
+	Nodes   map[string]*Node
+
+	imports   map[string]map[string]*Edge // from -> to -> edge
+	importers map[string]map[string]*Edge // to -> from -> edge
+}
+
+// NewGraph returns the import graph of pkgs found by FindAllPackage.
+// The packages imported by pkgs are also the nodes, but their imports are not
+// followed. The test files are not parsed, since the external test package may
+// import the packages which import the package under test, which is not a cycle.
+// The layer of each node is determined by conf the same as CheckDependency.
+// conf may be nil, which means no layers, and the project is inferred by bctx.
+//
+// The problems of parsing the packages and resolving the imports do not stop
+// building the graph, and are returned as the Diagnostics error with the graph.
+func NewGraph(fset *token.FileSet, bctx *BuildContext, pkgs []*build.Package, conf *Config) (*Graph, error) {
+	g := &Graph{
+		Nodes:     make(map[string]*Node),
+		imports:   make(map[string]map[string]*Edge),
+		importers: make(map[string]map[string]*Edge),
+	}
+	if conf == nil {
+		conf = new(Config)
+	}
+	project, projectDir := bctx.Project(conf)
+	g.Project = project
+	if project != conf.Project {
+		c := *conf
+		c.Project = project
+		conf = &c
+	}
+
+	var diags Diagnostics
+	for _, pkg := range pkgs {
+		importPath := bctx.importPathOf(pkg.Dir, project, projectDir)
+		node := g.node(importPath)
+		node.Name = pkg.Name
+		node.Dir = pkg.Dir
+		node.Class = bctx.classify(project, importPath, pkg.Dir)
+		node.Layer, _ = conf.PackageLayer(importPath, pkg.Name)
+
+		astpkgs, err := ParseDir(fset, bctx, pkg.Dir, GoFilesFilter(pkg, false), parser.ImportsOnly)
+		diags = append(diags, DiagnosticsOf(err)...)
+
+		for _, obj := range astpkgs {
+			for _, file := range obj.Files {
+				for _, imppkg := range file.Imports {
+					path, err := strconv.Unquote(imppkg.Path.Value)
+					if err != nil || path == "C" {
+						continue
+					}
+					resolved, dir, searched, ok := bctx.findImport(path, pkg.Dir)
+					if !ok {
+						diags = append(diags, Diagnostic{
+							Kind:     UnresolvedImport,
+							Pos:      fset.Position(imppkg.Pos()),
+							Message:  fmt.Sprintf("could not resolve import %q", path),
+							Searched: searched,
+						})
+						continue
+					}
+
+					to, ok := g.Nodes[resolved]
+					if !ok {
+						to = g.node(resolved)
+						to.Dir = dir
+						to.Class = bctx.classify(project, path, pkg.Dir)
+						if to.Class == ProjectImport {
+							to.Layer, _ = conf.LayerOf(resolved)
+						} else {
+							to.Layer, _ = conf.matchLayer(resolved)
+						}
+					}
+					g.addEdge(importPath, resolved, fset.Position(imppkg.Pos()))
+				}
+			}
+		}
+	}
+
+	for _, edges := range g.imports {
+		for _, e := range edges {
+			sortPositions(e.Positions)
+		}
+	}
+	diags.Sort()
+	return g, diags.Err()
+}
+
+func (g *Graph) node(importPath string) *Node {
+	n, ok := g.Nodes[importPath]
+	if !ok {
+		n = &Node{ImportPath: importPath}
+		g.Nodes[importPath] = n
+	}
+	return n
+}
+
+func (g *Graph) addEdge(from, to string, pos token.Position) {
+	e, ok := g.imports[from][to]
+	if !ok {
+		e = &Edge{From: from, To: to}
+		if g.imports[from] == nil {
+			g.imports[from] = make(map[string]*Edge)
+		}
+		if g.importers[to] == nil {
+			g.importers[to] = make(map[string]*Edge)
+		}
+		g.imports[from][to] = e
+		g.importers[to][from] = e
+	}
+	e.Positions = append(e.Positions, pos)
+}
+
+// Edge returns the edge from the from package to the to package, or nil if from
+// does not import to.
+func (g *Graph) Edge(from, to string) *Edge {
+	return g.imports[from][to]
+}
+
+// Edges returns all edges of g sorted by From and To.
+func (g *Graph) Edges() []*Edge {
+	var edges []*Edge
+	for _, from := range g.sortedNodes() {
+		for _, to := range sortedKeys(g.imports[from]) {
+			edges = append(edges, g.imports[from][to])
+		}
+	}
+	return edges
+}
+
+// Imports returns the sorted import paths which the importPath package imports directly.
+func (g *Graph) Imports(importPath string) []string {
+	return sortedKeys(g.imports[importPath])
+}
+
+// Importers returns the sorted import paths of the packages which import importPath directly.
+func (g *Graph) Importers(importPath string) []string {
+	return sortedKeys(g.importers[importPath])
+}
+
+// Reachable returns the sorted import paths of the packages which importPath
+// imports directly or indirectly, not including importPath itself unless it is
+// in a cycle.
+func (g *Graph) Reachable(importPath string) []string {
+	seen := make(map[string]bool)
+	stack := []string{importPath}
+	for len(stack) > 0 {
+		n := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for to := range g.imports[n] {
+			if !seen[to] {
+				seen[to] = true
+				stack = append(stack, to)
+			}
+		}
+	}
+
+	res := make([]string, 0, len(seen))
+	for n := range seen {
+		res = append(res, n)
+	}
+	sort.Strings(res)
+	return res
+}
+
+// ShortestPath returns the import paths of the shortest import chain from the from
+// package to the to package, including both ends. Returns nil if to is not reachable.
+// The lexically smallest chain wins among the chains of the same length.
+func (g *Graph) ShortestPath(from, to string) []string {
+	if _, ok := g.Nodes[from]; !ok {
+		return nil
+	}
+	prev := map[string]string{from: ""}
+	queue := []string{from}
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		if n == to {
+			var path []string
+			for ; n != from; n = prev[n] {
+				path = append(path, n)
+			}
+			path = append(path, from)
+			for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+				path[i], path[j] = path[j], path[i]
+			}
+			return path
+		}
+		for _, next := range sortedKeys(g.imports[n]) {
+			if _, ok := prev[next]; !ok {
+				prev[next] = n
+				queue = append(queue, next)
+			}
+		}
+	}
+	return nil
+}
+
+// LongestChains returns the longest import chain of each project package through the
+// project packages. The chain starts with the package itself, so the depth of the
+// package is the length of the chain minus one.
+// The lexically smallest chain wins among the chains of the same length.
+//
+// The chains are computed on the graph condensed by StronglyConnectedComponents,
+// so the imports between the packages in the same cycle are not followed, and
+// the packages in a cycle do not get the depth of each other.
+func (g *Graph) LongestChains() map[string][]string {
+	sccs := g.StronglyConnectedComponents()
+	sccOf := make(map[string]int)
+	for i, scc := range sccs {
+		for _, n := range scc {
+			sccOf[n] = i
+		}
+	}
+
+	// Each component imports only the components before it, so the chains of
+	// the imported packages are complete when the importer is visited.
+	chains := make(map[string][]string)
+	for i, scc := range sccs {
+		for _, n := range scc {
+			if g.Nodes[n].Class != ProjectImport {
+				continue
+			}
+			var next []string
+			for _, to := range sortedKeys(g.imports[n]) {
+				if g.Nodes[to].Class != ProjectImport || sccOf[to] == i {
+					continue
+				}
+				if chain := chains[to]; len(chain) > len(next) {
+					next = chain
+				}
+			}
+			chains[n] = append([]string{n}, next...)
+		}
+	}
+	return chains
+}
+
+// StronglyConnectedComponents returns the strongly connected components of g
+// in reverse topological order, that is, each component imports only the components
+// before it. The import paths in each component are sorted.
+//
+// The packages of Go can not import each other, so the component having the
+// multiple packages means the graph is broken.
+func (g *Graph) StronglyConnectedComponents() [][]string {
+	// Tarjan's algorithm.
+	var (
+		index   = make(map[string]int)
+		lowlink = make(map[string]int)
+		onStack = make(map[string]bool)
+		stack   []string
+		sccs    [][]string
+	)
+	var strongconnect func(v string)
+	strongconnect = func(v string) {
+		index[v] = len(index)
+		lowlink[v] = index[v]
+		stack = append(stack, v)
+		onStack[v] = true
+
+		for _, w := range sortedKeys(g.imports[v]) {
+			if _, ok := index[w]; !ok {
+				strongconnect(w)
+				if lowlink[w] < lowlink[v] {
+					lowlink[v] = lowlink[w]
+				}
+			} else if onStack[w] && index[w] < lowlink[v] {
+				lowlink[v] = index[w]
+			}
+		}
+
+		if lowlink[v] == index[v] {
+			var scc []string
+			for {
+				w := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[w] = false
+				scc = append(scc, w)
+				if w == v {
+					break
+				}
+			}
+			sort.Strings(scc)
+			sccs = append(sccs, scc)
+		}
+	}
+
+	for _, v := range g.sortedNodes() {
+		if _, ok := index[v]; !ok {
+			strongconnect(v)
+		}
+	}
+	return sccs
+}
+
+// Aggregate returns the graph whose nodes are the groups of the nodes of g by key,
+// such as the layer. The nodes whose key is empty are dropped, and the imports in
+// the same group are not the edges. The edges of the aggregated graph have the
+// positions of all imports between the groups.
+//
+// The Layer of the aggregated node is set if all nodes of the group have the same
+// one, and Dir is set if the group has only one node. The Class is ProjectImport
+// if any nodes of the group are, otherwise the class of the first node by import path.
+func (g *Graph) Aggregate(key func(*Node) string) *Graph {
+	ag := &Graph{
+		Nodes:     make(map[string]*Node),
+		imports:   make(map[string]map[string]*Edge),
+		importers: make(map[string]map[string]*Edge),
+	}
+	keys := make(map[string]string, len(g.Nodes))
+	size := make(map[string]int)
+	for _, path := range g.sortedNodes() {
+		n := g.Nodes[path]
+		k := key(n)
+		if k == "" {
+			continue
+		}
+		keys[path] = k
+
+		an, ok := ag.Nodes[k]
+		if !ok {
+			an = &Node{ImportPath: k, Dir: n.Dir, Layer: n.Layer, Class: n.Class}
+			ag.Nodes[k] = an
+		}
+		size[k]++
+		if size[k] > 1 {
+			an.Dir = ""
+		}
+		if an.Layer != n.Layer {
+			an.Layer = ""
+		}
+		if n.Class == ProjectImport {
+			an.Class = ProjectImport
+		}
+	}
+
+	for _, e := range g.Edges() {
+		from, to := keys[e.From], keys[e.To]
+		if from == "" || to == "" || from == to {
+			continue
+		}
+		for _, pos := range e.Positions {
+			ag.addEdge(from, to, pos)
+		}
+	}
+	for _, edges := range ag.imports {
+		for _, e := range edges {
+			sortPositions(e.Positions)
+		}
+	}
+	return ag
+}
+
+func (g *Graph) sortedNodes() []string {
+	nodes := make([]string, 0, len(g.Nodes))
+	for n := range g.Nodes {
+		nodes = append(nodes, n)
+	}
+	sort.Strings(nodes)
+	return nodes
+}
+
+func sortedKeys(m map[string]*Edge) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortPositions(positions []token.Position) {
+	sort.Slice(positions, func(i, j int) bool {
+		pi, pj := positions[i], positions[j]
+		if pi.Filename != pj.Filename {
+			return pi.Filename < pj.Filename
+		}
+		return pi.Offset < pj.Offset
+	})
+}
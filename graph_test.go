@@ -0,0 +1,228 @@
+// Copyright 2017 The go-importlint Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package importlint
+
+import (
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// newTestGraph returns the graph of the edges, where each edge is the pair of
+// the from and to import paths.
+func newTestGraph(edges ...[2]string) *Graph {
+	g := &Graph{
+		Nodes:     make(map[string]*Node),
+		imports:   make(map[string]map[string]*Edge),
+		importers: make(map[string]map[string]*Edge),
+	}
+	for _, e := range edges {
+		g.node(e[0])
+		g.node(e[1])
+		g.addEdge(e[0], e[1], token.Position{})
+	}
+	return g
+}
+
+func TestGraphShortestPath(t *testing.T) {
+	g := newTestGraph(
+		[2]string{"a", "c"},
+		[2]string{"a", "b"},
+		[2]string{"b", "d"},
+		[2]string{"c", "d"},
+		[2]string{"d", "e"},
+		[2]string{"e", "d"},
+	)
+	tests := []struct {
+		from, to string
+		want     []string
+	}{
+		// the lexically smallest chain wins among the same length.
+		{"a", "d", []string{"a", "b", "d"}},
+		{"a", "e", []string{"a", "b", "d", "e"}},
+		{"a", "a", []string{"a"}},
+		{"e", "d", []string{"e", "d"}},
+		{"d", "a", nil},
+		{"x", "a", nil},
+	}
+	for _, tt := range tests {
+		if got := g.ShortestPath(tt.from, tt.to); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ShortestPath(%q, %q) = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestGraphReachable(t *testing.T) {
+	g := newTestGraph(
+		[2]string{"a", "b"},
+		[2]string{"b", "c"},
+		[2]string{"c", "b"},
+		[2]string{"d", "a"},
+	)
+	tests := []struct {
+		importPath string
+		want       []string
+	}{
+		{"d", []string{"a", "b", "c"}},
+		{"a", []string{"b", "c"}},
+		// the package in a cycle reaches itself.
+		{"b", []string{"b", "c"}},
+		{"x", []string{}},
+	}
+	for _, tt := range tests {
+		if got := g.Reachable(tt.importPath); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Reachable(%q) = %v, want %v", tt.importPath, got, tt.want)
+		}
+	}
+}
+
+func TestGraphStronglyConnectedComponents(t *testing.T) {
+	g := newTestGraph(
+		[2]string{"a", "b"},
+		[2]string{"b", "c"},
+		[2]string{"c", "a"},
+		[2]string{"c", "d"},
+		[2]string{"d", "e"},
+		[2]string{"f", "a"},
+	)
+	// each component imports only the components before it.
+	want := [][]string{{"e"}, {"d"}, {"a", "b", "c"}, {"f"}}
+	if got := g.StronglyConnectedComponents(); !reflect.DeepEqual(got, want) {
+		t.Errorf("StronglyConnectedComponents() = %v, want %v", got, want)
+	}
+}
+
+func TestNewGraphNilConfig(t *testing.T) {
+	gopath, err := filepath.Abs(filepath.Join("testdata", "resolve"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	bc := NewBuildContext(filepath.Join(gopath, "src", "app"), WithGOROOT(""), WithGOPATH(gopath))
+	pkgs, err := bc.FindAllPackage(nil, ExcludeVendor)
+	if err != nil {
+		t.Fatalf("FindAllPackage() error = %v", err)
+	}
+
+	g, err := NewGraph(token.NewFileSet(), &bc, pkgs, nil)
+	if err != nil {
+		t.Fatalf("NewGraph() error = %v", err)
+	}
+	if g.Project != "app" {
+		t.Errorf("Project = %q, want %q", g.Project, "app")
+	}
+	want := []string{"app/vendor/lib", "other", "vendor/shared"}
+	if got := g.Imports("app/cmd"); !reflect.DeepEqual(got, want) {
+		t.Errorf("Imports(app/cmd) = %v, want %v", got, want)
+	}
+	for path, n := range g.Nodes {
+		if n.Layer != "" {
+			t.Errorf("layer of %s = %q, want no layer", path, n.Layer)
+		}
+	}
+}
+
+func TestNewGraphExternalTest(t *testing.T) {
+	g := newFixtureGraph(t)
+	tests := []struct {
+		importPath string
+		want       []string
+	}{
+		// the imports of the external test packages are not the edges.
+		{"shop/domain", []string{}},
+		{"shop/billing", []string{}},
+		{"shop/payments", []string{"shop/billing"}},
+		{"shop/infrastructure", []string{"shop/application"}},
+	}
+	for _, tt := range tests {
+		if got := g.Imports(tt.importPath); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Imports(%q) = %v, want %v", tt.importPath, got, tt.want)
+		}
+	}
+}
+
+// newFixtureGraph returns the graph of the shop project in testdata/graph.
+func newFixtureGraph(t *testing.T) *Graph {
+	gopath, err := filepath.Abs(filepath.Join("testdata", "graph"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	bc := NewBuildContext(filepath.Join(gopath, "src", "shop"), WithGOROOT(""), WithGOPATH(gopath))
+	pkgs, err := bc.FindAllPackage(nil, ExcludeVendor)
+	if err != nil {
+		t.Fatalf("FindAllPackage() error = %v", err)
+	}
+	g, err := NewGraph(token.NewFileSet(), &bc, pkgs, nil)
+	if err != nil {
+		t.Fatalf("NewGraph() error = %v", err)
+	}
+	return g
+}
+
+func TestGraphLongestChains(t *testing.T) {
+	tests := []struct {
+		name     string
+		g        *Graph
+		external []string
+		want     map[string][]string
+	}{
+		{
+			name: "acyclic",
+			g: newTestGraph(
+				[2]string{"a", "c"},
+				[2]string{"a", "b"},
+				[2]string{"b", "c"},
+				[2]string{"a", "fmt"},
+			),
+			external: []string{"fmt"},
+			want: map[string][]string{
+				"a": {"a", "b", "c"},
+				"b": {"b", "c"},
+				"c": {"c"},
+			},
+		},
+		{
+			name: "same length",
+			g: newTestGraph(
+				[2]string{"a", "c"},
+				[2]string{"a", "b"},
+				[2]string{"b", "d"},
+				[2]string{"c", "d"},
+			),
+			want: map[string][]string{
+				"a": {"a", "b", "d"},
+				"b": {"b", "d"},
+				"c": {"c", "d"},
+				"d": {"d"},
+			},
+		},
+		{
+			// the imports in the cycle of b and c are not followed.
+			name: "cycle",
+			g: newTestGraph(
+				[2]string{"e", "a"},
+				[2]string{"a", "b"},
+				[2]string{"b", "c"},
+				[2]string{"c", "b"},
+				[2]string{"c", "d"},
+			),
+			want: map[string][]string{
+				"a": {"a", "b"},
+				"b": {"b"},
+				"c": {"c", "d"},
+				"d": {"d"},
+				"e": {"e", "a", "b"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		for _, n := range tt.external {
+			tt.g.Nodes[n].Class = ThirdPartyImport
+		}
+		if got := tt.g.LongestChains(); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: LongestChains() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
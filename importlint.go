@@ -8,32 +8,74 @@ import (
 	"go/build"
 	"path/filepath"
 	"strings"
+
+	"golang.org/x/tools/go/buildutil"
 )
 
+// BuildContext represents the build context of the linting project.
+// It owns a copy of build.Default, so multiple BuildContexts can be used concurrently.
 type BuildContext struct {
 	ctxt    *build.Context
 	root    string
 	gopaths []string
+	gb      bool
 }
 
-func NewBuildContext(root string) BuildContext {
+// Option configures the BuildContext.
+type Option func(*build.Context)
+
+// WithGOROOT sets the GOROOT of the BuildContext.
+func WithGOROOT(goroot string) Option {
+	return func(ctxt *build.Context) {
+		ctxt.GOROOT = goroot
+	}
+}
+
+// WithGOPATH sets the list of GOPATH of the BuildContext.
+func WithGOPATH(gopaths ...string) Option {
+	return func(ctxt *build.Context) {
+		ctxt.GOPATH = strings.Join(gopaths, string(filepath.ListSeparator))
+	}
+}
+
+// WithBuildTags sets the build tags of the BuildContext.
+func WithBuildTags(tags ...string) Option {
+	return func(ctxt *build.Context) {
+		ctxt.BuildTags = tags
+	}
+}
+
+// WithCgoEnabled sets whether the cgo files are included.
+func WithCgoEnabled(enabled bool) Option {
+	return func(ctxt *build.Context) {
+		ctxt.CgoEnabled = enabled
+	}
+}
+
+// NewBuildContext returns the BuildContext of root directory configured by opts.
+func NewBuildContext(root string, opts ...Option) BuildContext {
+	if abs, err := filepath.Abs(root); err == nil {
+		root = abs
+	}
+
+	ctxt := build.Default // make a copy
+	for _, opt := range opts {
+		opt(&ctxt)
+	}
+
 	bc := BuildContext{
-		ctxt:    &build.Default,
+		ctxt:    &ctxt,
 		root:    root,
-		gopaths: []string{build.Default.GOPATH},
+		gopaths: filepath.SplitList(ctxt.GOPATH),
 	}
 
 	if gbroot, yes := isGb(root); yes { // gb directory structure
 		bc.root = gbroot
-		bc.gopaths = []string{root, filepath.Join(root, "vendor")}
-		bc.ctxt.GOPATH = root + string(filepath.ListSeparator) + filepath.Join(root, "vendor")
+		bc.gb = true
+		bc.gopaths = []string{gbroot, filepath.Join(gbroot, "vendor")}
+		bc.ctxt.GOPATH = strings.Join(bc.gopaths, string(filepath.ListSeparator))
 		bc.ctxt.SplitPathList = bc.splitPathList
 		bc.ctxt.JoinPath = bc.joinPath
-	} else { // general directory structure
-		// split GOPATH if users set multiple directory path
-		if paths := strings.Split(bc.ctxt.GOPATH, string(filepath.ListSeparator)); len(paths) >= 2 {
-			bc.gopaths = paths
-		}
 	}
 
 	return bc
@@ -42,3 +84,24 @@ func NewBuildContext(root string) BuildContext {
 func (b *BuildContext) Context() *build.Context {
 	return b.ctxt
 }
+
+// WithOverlay returns a copy of b whose files in overlay take precedence over
+// the files on the file system.
+func (b *BuildContext) WithOverlay(overlay map[string][]byte) BuildContext {
+	bc := *b
+	bc.ctxt = buildutil.OverlayContext(b.ctxt, overlay)
+	return bc
+}
+
+// WithPlatform returns a copy of b which evaluates the build constraints for
+// goos, goarch and build tags.
+func (b *BuildContext) WithPlatform(goos, goarch string, tags []string) BuildContext {
+	ctxt := *b.ctxt // make a copy
+	ctxt.GOOS = goos
+	ctxt.GOARCH = goarch
+	ctxt.BuildTags = tags
+
+	bc := *b
+	bc.ctxt = &ctxt
+	return bc
+}
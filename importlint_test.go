@@ -0,0 +1,39 @@
+// Copyright 2017 The go-importlint Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package importlint
+
+import (
+	"go/build"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestNewBuildContextOptions(t *testing.T) {
+	def := build.Default
+	def.BuildTags = append([]string(nil), build.Default.BuildTags...)
+
+	bc := NewBuildContext(".", WithGOROOT(""), WithGOPATH("/a", "/b"), WithBuildTags("foo", "bar"), WithCgoEnabled(!def.CgoEnabled))
+	if want := "/a" + string(filepath.ListSeparator) + "/b"; bc.ctxt.GOPATH != want {
+		t.Errorf("GOPATH = %q, want %q", bc.ctxt.GOPATH, want)
+	}
+	if want := []string{"foo", "bar"}; !reflect.DeepEqual(bc.ctxt.BuildTags, want) {
+		t.Errorf("BuildTags = %v, want %v", bc.ctxt.BuildTags, want)
+	}
+	pbc := bc.WithPlatform("plan9", "386", []string{"baz"})
+	if bc.ctxt.GOOS != def.GOOS || !reflect.DeepEqual(bc.ctxt.BuildTags, []string{"foo", "bar"}) {
+		t.Errorf("WithPlatform() modifies the original BuildContext: GOOS = %q, BuildTags = %v", bc.ctxt.GOOS, bc.ctxt.BuildTags)
+	}
+	if pbc.ctxt.GOOS != "plan9" || pbc.ctxt.GOPATH != bc.ctxt.GOPATH {
+		t.Errorf("WithPlatform() = GOOS %q, GOPATH %q, want plan9 and %q", pbc.ctxt.GOOS, pbc.ctxt.GOPATH, bc.ctxt.GOPATH)
+	}
+
+	// the options must be applied to the copy of build.Default.
+	got := build.Default
+	if got.GOROOT != def.GOROOT || got.GOPATH != def.GOPATH || got.GOOS != def.GOOS ||
+		got.CgoEnabled != def.CgoEnabled || !reflect.DeepEqual(got.BuildTags, def.BuildTags) {
+		t.Errorf("build.Default is modified: %+v, want %+v", got, def)
+	}
+}
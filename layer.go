@@ -0,0 +1,210 @@
+// Copyright 2017 The go-importlint Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package importlint
+
+import (
+	"fmt"
+	"go/token"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// LayerOf returns the layer name of importPath.
+// The layer is the layer whose Packages patterns match importPath, or the deepest
+// path element which is defined in the Layers config if no patterns match.
+func (c *Config) LayerOf(importPath string) (string, bool) {
+	if layer, ok := c.matchLayer(importPath); ok {
+		return layer, true
+	}
+
+	elems := strings.Split(importPath, "/")
+	for i := len(elems) - 1; i >= 0; i-- {
+		if lc, ok := c.Layers[elems[i]]; ok && len(lc.Packages) == 0 {
+			return elems[i], true
+		}
+	}
+	return "", false
+}
+
+// PackageLayer returns the layer name of the package of importPath and the package name.
+// The layer is the layer whose Packages patterns match importPath, or the package name
+// if it is defined in the Layers config.
+func (c *Config) PackageLayer(importPath, name string) (string, bool) {
+	if importPath != "" {
+		if layer, ok := c.matchLayer(importPath); ok {
+			return layer, true
+		}
+	}
+	if lc, ok := c.Layers[name]; ok && len(lc.Packages) == 0 {
+		return name, true
+	}
+	return "", false
+}
+
+// matchLayer returns the layer which has the longest Packages pattern matching importPath.
+func (c *Config) matchLayer(importPath string) (string, bool) {
+	var (
+		found   string
+		longest = -1
+	)
+	for layer, lc := range c.Layers {
+		for _, pattern := range lc.Packages {
+			if c.Project != "" {
+				pattern = path.Join(c.Project, pattern)
+			}
+			if !matchPattern(pattern, importPath) {
+				continue
+			}
+			// prefer the longest pattern, and the smaller layer name for the determinism.
+			if len(pattern) > longest || len(pattern) == longest && layer < found {
+				found, longest = layer, len(pattern)
+			}
+		}
+	}
+	return found, longest >= 0
+}
+
+// matchPattern reports whether name matches pattern, where "..." matches any string
+// the same as the go command.
+func matchPattern(pattern, name string) bool {
+	re := regexp.QuoteMeta(pattern)
+	re = strings.Replace(re, `\.\.\.`, `.*`, -1)
+	// Special case: foo/... matches foo too.
+	if strings.HasSuffix(re, `/.*`) {
+		re = re[:len(re)-len(`/.*`)] + `(/.*)?`
+	}
+	matched, _ := regexp.MatchString(`^`+re+`$`, name)
+	return matched
+}
+
+// CanImport reports whether the from layer is allowed to import the to layer.
+func (c *Config) CanImport(from, to string) bool {
+	if from == to {
+		return true
+	}
+	for _, l := range c.Layers[from].Allow {
+		if l == to {
+			return true
+		}
+	}
+	return false
+}
+
+// LayerOrder returns the layer names ordered so that each layer comes after the layers
+// which it is allowed to import. The layers allowed to import each other directly
+// or indirectly can not be ordered, and are ordered by name.
+func (c *Config) LayerOrder() []string {
+	var order []string
+	placed := make(map[string]bool, len(c.Layers))
+	for len(order) < len(c.Layers) {
+		var ready, rest []string
+		for layer, lc := range c.Layers {
+			if placed[layer] {
+				continue
+			}
+			rest = append(rest, layer)
+			ok := true
+			for _, dep := range lc.Allow {
+				if _, defined := c.Layers[dep]; defined && dep != layer && !placed[dep] {
+					ok = false
+					break
+				}
+			}
+			if ok {
+				ready = append(ready, layer)
+			}
+		}
+		if len(ready) == 0 {
+			ready = rest // break the cycle
+		}
+		sort.Strings(ready)
+		order = append(order, ready[0])
+		placed[ready[0]] = true
+	}
+	return order
+}
+
+// Violation represents the import which is not allowed by the Layers config.
+type Violation struct {
+	Pos         token.Position
+	End         token.Position
+	Layer       string
+	Import      string
+	ImportLayer string
+	// Class is the class of the imported package.
+	Class ImportClass
+
+	// Resolved is the import path which the Import resolved to following the
+	// vendor lookup, e.g. "foo/vendor/bar". Dir is the directory of that.
+	Resolved string
+	Dir      string
+
+	// Platforms is the list of "GOOS/GOARCH" which the violation appears on.
+	// It is set by MergeViolations.
+	Platforms []string
+}
+
+func (v Violation) String() string {
+	if len(v.Platforms) > 0 {
+		return fmt.Sprintf("%s: %s [%s]", v.Pos, v.Message(), strings.Join(v.Platforms, " "))
+	}
+	return fmt.Sprintf("%s: %s", v.Pos, v.Message())
+}
+
+// Message returns the human readable description of v without position.
+func (v Violation) Message() string {
+	if v.Resolved != "" && v.Resolved != v.Import {
+		return fmt.Sprintf("%s layer must not import %q (%s layer, resolved to %q)", v.Layer, v.Import, v.ImportLayer, v.Resolved)
+	}
+	return fmt.Sprintf("%s layer must not import %q (%s layer)", v.Layer, v.Import, v.ImportLayer)
+}
+
+// MergeViolations merges the violations found on each platform keyed by "GOOS/GOARCH",
+// and records the platforms which each violation appears on.
+func MergeViolations(byPlatform map[string][]Violation) []Violation {
+	type key struct {
+		filename string
+		offset   int
+		imp      string
+	}
+	merged := make(map[key]*Violation)
+	var violations []*Violation
+
+	for platform, vs := range byPlatform {
+		for _, v := range vs {
+			k := key{filename: v.Pos.Filename, offset: v.Pos.Offset, imp: v.Import}
+			m, ok := merged[k]
+			if !ok {
+				m = new(Violation)
+				*m = v
+				m.Platforms = nil
+				merged[k] = m
+				violations = append(violations, m)
+			}
+			m.Platforms = append(m.Platforms, platform)
+		}
+	}
+
+	res := make([]Violation, 0, len(violations))
+	for _, v := range violations {
+		sort.Strings(v.Platforms)
+		res = append(res, *v)
+	}
+	sortViolations(res)
+
+	return res
+}
+
+func sortViolations(violations []Violation) {
+	sort.Slice(violations, func(i, j int) bool {
+		pi, pj := violations[i].Pos, violations[j].Pos
+		if pi.Filename != pj.Filename {
+			return pi.Filename < pj.Filename
+		}
+		return pi.Offset < pj.Offset
+	})
+}
@@ -0,0 +1,67 @@
+// Copyright 2017 The go-importlint Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package importlint
+
+import (
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestMergeViolationsPlatforms(t *testing.T) {
+	gopath, err := filepath.Abs(filepath.Join("testdata", "platform"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := filepath.Join(gopath, "src", "app", "domain")
+	conf := &Config{
+		Project: "app",
+		Layers: map[string]LayerConfig{
+			"domain":         {},
+			"application":    {},
+			"infrastructure": {},
+		},
+	}
+
+	bc := NewBuildContext(dir, WithGOROOT(""), WithGOPATH(gopath))
+	byPlatform := make(map[string][]Violation)
+	for _, platform := range [][2]string{{"linux", "amd64"}, {"windows", "amd64"}} {
+		pbc := bc.WithPlatform(platform[0], platform[1], nil)
+		pkg, err := pbc.ctxt.ImportDir(dir, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fset := token.NewFileSet()
+		pkgs, err := ParseDir(fset, &pbc, dir, GoFilesFilter(pkg, true), parser.ImportsOnly)
+		if err != nil {
+			t.Fatal(err)
+		}
+		violations, err := CheckDependency(fset, &pbc, pkgs, conf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		byPlatform[platform[0]+"/"+platform[1]] = violations
+	}
+
+	type violation struct {
+		file      string
+		imp       string
+		platforms []string
+	}
+	var got []violation
+	for _, v := range MergeViolations(byPlatform) {
+		got = append(got, violation{filepath.Base(v.Pos.Filename), v.Import, v.Platforms})
+	}
+	want := []violation{
+		{"domain.go", "app/application", []string{"linux/amd64", "windows/amd64"}},
+		{"domain_linux.go", "app/infrastructure", []string{"linux/amd64"}},
+		{"domain_windows.go", "app/infrastructure", []string{"windows/amd64"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("MergeViolations() = %v, want %v", got, want)
+	}
+}
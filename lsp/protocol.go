@@ -0,0 +1,128 @@
+// Copyright 2017 The go-importlint Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lsp
+
+import "encoding/json"
+
+// The subset of Language Server Protocol types used by importlint.
+
+type request struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id,omitempty"`
+	Method  string           `json:"method"`
+	Params  json.RawMessage  `json:"params,omitempty"`
+}
+
+type response struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id"`
+	Result  interface{}      `json:"result"`
+	Error   *responseError   `json:"error,omitempty"`
+}
+
+type notification struct {
+	JSONRPC string      `json:"jsonrpc"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params"`
+}
+
+type responseError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+const (
+	codeParseError     = -32700
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+)
+
+type initializeParams struct {
+	RootURI string `json:"rootUri"`
+}
+
+type initializeResult struct {
+	Capabilities serverCapabilities `json:"capabilities"`
+	ServerInfo   serverInfo         `json:"serverInfo"`
+}
+
+type serverInfo struct {
+	Name string `json:"name"`
+}
+
+type serverCapabilities struct {
+	TextDocumentSync textDocumentSyncOptions `json:"textDocumentSync"`
+}
+
+type textDocumentSyncOptions struct {
+	OpenClose bool        `json:"openClose"`
+	Change    int         `json:"change"`
+	Save      saveOptions `json:"save"`
+}
+
+type saveOptions struct {
+	IncludeText bool `json:"includeText"`
+}
+
+// textDocumentSyncKindFull means the documents are synced by always sending the full content.
+const textDocumentSyncKindFull = 1
+
+type textDocumentIdentifier struct {
+	URI string `json:"uri"`
+}
+
+type textDocumentItem struct {
+	URI  string `json:"uri"`
+	Text string `json:"text"`
+}
+
+type didOpenTextDocumentParams struct {
+	TextDocument textDocumentItem `json:"textDocument"`
+}
+
+type didChangeTextDocumentParams struct {
+	TextDocument   textDocumentIdentifier           `json:"textDocument"`
+	ContentChanges []textDocumentContentChangeEvent `json:"contentChanges"`
+}
+
+type textDocumentContentChangeEvent struct {
+	Text string `json:"text"`
+}
+
+type didSaveTextDocumentParams struct {
+	TextDocument textDocumentIdentifier `json:"textDocument"`
+	Text         *string                `json:"text,omitempty"`
+}
+
+type didCloseTextDocumentParams struct {
+	TextDocument textDocumentIdentifier `json:"textDocument"`
+}
+
+type position struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+type lspRange struct {
+	Start position `json:"start"`
+	End   position `json:"end"`
+}
+
+type diagnostic struct {
+	Range    lspRange `json:"range"`
+	Severity int      `json:"severity"`
+	Source   string   `json:"source"`
+	Message  string   `json:"message"`
+}
+
+const (
+	severityError   = 1
+	severityWarning = 2
+)
+
+type publishDiagnosticsParams struct {
+	URI         string       `json:"uri"`
+	Diagnostics []diagnostic `json:"diagnostics"`
+}
@@ -0,0 +1,352 @@
+// Copyright 2017 The go-importlint Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package lsp provides the minimal Language Server Protocol server which
+// publishes importlint diagnostics of opened documents.
+package lsp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	importlint "github.com/zchee/go-importlint"
+)
+
+// Server is the Language Server Protocol server over the stream.
+type Server struct {
+	resolver *importlint.ConfigResolver
+
+	mu   sync.Mutex
+	w    io.Writer
+	docs map[string][]byte // filename to unsaved buffer contents
+	root string
+}
+
+// NewServer returns the new Server which checks documents by the config resolved by resolver.
+func NewServer(resolver *importlint.ConfigResolver) *Server {
+	return &Server{
+		resolver: resolver,
+		docs:     make(map[string][]byte),
+	}
+}
+
+// Serve reads requests from r and writes responses to w until the exit notification or EOF.
+func (s *Server) Serve(r io.Reader, w io.Writer) error {
+	s.w = w
+	br := bufio.NewReader(r)
+
+	shutdown := false
+	for {
+		body, err := readMessage(br)
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+
+		var req request
+		if err := json.Unmarshal(body, &req); err != nil {
+			if err := s.reply(nil, nil, &responseError{Code: codeParseError, Message: err.Error()}); err != nil {
+				return err
+			}
+			continue
+		}
+
+		switch req.Method {
+		case "exit":
+			if !shutdown {
+				return errors.New("exit notification received before shutdown")
+			}
+			return nil
+		case "shutdown":
+			shutdown = true
+			err = s.reply(req.ID, nil, nil)
+		default:
+			var result interface{}
+			var rerr *responseError
+			result, rerr, err = s.handle(req.Method, req.Params)
+			if err == nil && req.ID != nil {
+				err = s.reply(req.ID, result, rerr)
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// handle handles the request or notification of method. The returned error is
+// the failure of writing to the client, which stops the server.
+func (s *Server) handle(method string, params json.RawMessage) (interface{}, *responseError, error) {
+	switch method {
+	case "initialize":
+		var p initializeParams
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, &responseError{Code: codeInvalidParams, Message: err.Error()}, nil
+		}
+		s.root = uriToPath(p.RootURI)
+		return initializeResult{
+			Capabilities: serverCapabilities{
+				TextDocumentSync: textDocumentSyncOptions{
+					OpenClose: true,
+					Change:    textDocumentSyncKindFull,
+					Save:      saveOptions{IncludeText: true},
+				},
+			},
+			ServerInfo: serverInfo{Name: "importlint"},
+		}, nil, nil
+
+	case "textDocument/didOpen":
+		var p didOpenTextDocumentParams
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, &responseError{Code: codeInvalidParams, Message: err.Error()}, nil
+		}
+		return nil, nil, s.update(p.TextDocument.URI, []byte(p.TextDocument.Text))
+
+	case "textDocument/didChange":
+		var p didChangeTextDocumentParams
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, &responseError{Code: codeInvalidParams, Message: err.Error()}, nil
+		}
+		if n := len(p.ContentChanges); n > 0 {
+			return nil, nil, s.update(p.TextDocument.URI, []byte(p.ContentChanges[n-1].Text))
+		}
+
+	case "textDocument/didSave":
+		var p didSaveTextDocumentParams
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, &responseError{Code: codeInvalidParams, Message: err.Error()}, nil
+		}
+		if p.Text != nil {
+			return nil, nil, s.update(p.TextDocument.URI, []byte(*p.Text))
+		}
+		return nil, nil, s.update(p.TextDocument.URI, nil)
+
+	case "textDocument/didClose":
+		var p didCloseTextDocumentParams
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, &responseError{Code: codeInvalidParams, Message: err.Error()}, nil
+		}
+		s.mu.Lock()
+		delete(s.docs, uriToPath(p.TextDocument.URI))
+		s.mu.Unlock()
+		return nil, nil, s.publish(p.TextDocument.URI, []diagnostic{})
+
+	case "initialized", "$/cancelRequest", "workspace/didChangeConfiguration":
+		// nothing to do
+
+	default:
+		return nil, &responseError{Code: codeMethodNotFound, Message: fmt.Sprintf("method not found: %s", method)}, nil
+	}
+
+	return nil, nil, nil
+}
+
+// update stores the buffer contents of uri and publishes its diagnostics.
+// The nil content means the document is the same as the file on disk.
+func (s *Server) update(uri string, content []byte) error {
+	filename := uriToPath(uri)
+
+	s.mu.Lock()
+	if content != nil {
+		s.docs[filename] = content
+	}
+	overlay := make(map[string][]byte, len(s.docs))
+	for k, v := range s.docs {
+		overlay[k] = v
+	}
+	s.mu.Unlock()
+
+	return s.publish(uri, s.diagnostics(filename, overlay))
+}
+
+func (s *Server) diagnostics(filename string, overlay map[string][]byte) []diagnostic {
+	dir := filepath.Dir(filename)
+	root := s.root
+	if root == "" {
+		root = dir
+	}
+	bc := importlint.NewBuildContext(root)
+	bc = bc.WithOverlay(overlay)
+
+	fset := token.NewFileSet()
+	filter := func(fi os.FileInfo) bool {
+		return filepath.Join(dir, fi.Name()) == filename
+	}
+	pkgs, err := importlint.ParseDir(fset, &bc, dir, filter, parser.ImportsOnly)
+
+	diags := []diagnostic{}
+	conf, _, cerr := s.resolver.Resolve(dir)
+	if cerr != nil {
+		return append(diags, diagnostic{
+			Severity: severityError,
+			Source:   "importlint",
+			Message:  cerr.Error(),
+		})
+	}
+	violations, cerr := importlint.CheckDependency(fset, &bc, pkgs, conf)
+
+	src, ok := overlay[filename]
+	if !ok {
+		src, _ = ioutil.ReadFile(filename) // the byte columns are used if unreadable
+	}
+	for _, d := range append(importlint.DiagnosticsOf(err), importlint.DiagnosticsOf(cerr)...) {
+		if d.Pos.Filename != filename {
+			continue
+		}
+		pos := lspPosition(src, d.Pos)
+		msg := fmt.Sprintf("%s: %s", d.Kind, d.Message)
+		if len(d.Searched) > 0 {
+			msg += "\nsearched:\n\t" + strings.Join(d.Searched, "\n\t")
+		}
+		diags = append(diags, diagnostic{
+			Range:    lspRange{Start: pos, End: pos},
+			Severity: severityWarning,
+			Source:   "importlint",
+			Message:  msg,
+		})
+	}
+	for _, v := range violations {
+		diags = append(diags, diagnostic{
+			Range: lspRange{
+				Start: lspPosition(src, v.Pos),
+				End:   lspPosition(src, v.End),
+			},
+			Severity: severityError,
+			Source:   "importlint",
+			Message:  v.Message(),
+		})
+	}
+	return diags
+}
+
+// lspPosition converts the 1-based line and byte column of pos to the 0-based
+// position of LSP, whose character offset is counted in UTF-16 code units of the
+// line in src. The unknown line or column is clamped to zero, and the byte column
+// is used if src is nil.
+func lspPosition(src []byte, pos token.Position) position {
+	line, col := pos.Line-1, pos.Column-1
+	if line < 0 {
+		return position{}
+	}
+	if col < 0 {
+		col = 0
+	}
+	if src == nil {
+		return position{Line: line, Character: col}
+	}
+
+	text := src
+	for i := 0; i < line; i++ {
+		j := bytes.IndexByte(text, '\n')
+		if j < 0 {
+			return position{Line: line, Character: col} // src is not the parsed content
+		}
+		text = text[j+1:]
+	}
+	if j := bytes.IndexByte(text, '\n'); j >= 0 {
+		text = text[:j]
+	}
+	if col > len(text) {
+		col = len(text)
+	}
+
+	n := 0
+	for _, r := range string(text[:col]) {
+		if r >= 0x10000 {
+			n += 2 // surrogate pair
+		} else {
+			n++
+		}
+	}
+	return position{Line: line, Character: n}
+}
+
+func (s *Server) publish(uri string, diags []diagnostic) error {
+	return s.write(notification{
+		JSONRPC: "2.0",
+		Method:  "textDocument/publishDiagnostics",
+		Params:  publishDiagnosticsParams{URI: uri, Diagnostics: diags},
+	})
+}
+
+func (s *Server) reply(id *json.RawMessage, result interface{}, rerr *responseError) error {
+	return s.write(response{JSONRPC: "2.0", ID: id, Result: result, Error: rerr})
+}
+
+func (s *Server) write(msg interface{}) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := fmt.Fprintf(s.w, "Content-Length: %d\r\n\r\n", len(body)); err != nil {
+		return err
+	}
+	_, err = s.w.Write(body)
+	return err
+}
+
+// readMessage reads the base protocol message which consists of the header and content part.
+func readMessage(r *bufio.Reader) ([]byte, error) {
+	length := -1
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			break
+		}
+		if v, ok := cutHeader(line, "Content-Length"); ok {
+			length, err = strconv.Atoi(v)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid Content-Length header %q", v)
+			}
+		}
+	}
+	if length < 0 {
+		return nil, errors.New("missing Content-Length header")
+	}
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+func cutHeader(line, name string) (string, bool) {
+	i := strings.IndexByte(line, ':')
+	if i < 0 || !strings.EqualFold(strings.TrimSpace(line[:i]), name) {
+		return "", false
+	}
+	return strings.TrimSpace(line[i+1:]), true
+}
+
+func uriToPath(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return uri
+	}
+	return filepath.FromSlash(u.Path)
+}
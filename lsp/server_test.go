@@ -0,0 +1,54 @@
+// Copyright 2017 The go-importlint Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lsp
+
+import (
+	"fmt"
+	"go/token"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestLSPPosition(t *testing.T) {
+	src := []byte("package a\n\nimport \"é/b\" // 😀\nimport \"😀/c\"\n")
+	tests := []struct {
+		name string
+		src  []byte
+		pos  token.Position
+		want position
+	}{
+		{"ascii", src, token.Position{Line: 1, Column: 9}, position{Line: 0, Character: 8}},
+		{"after two byte rune", src, token.Position{Line: 3, Column: 12}, position{Line: 2, Character: 10}},
+		{"end of line with four byte rune", src, token.Position{Line: 3, Column: 22}, position{Line: 2, Character: 18}},
+		{"after four byte rune", src, token.Position{Line: 4, Column: 13}, position{Line: 3, Character: 10}},
+		{"column beyond the line", src, token.Position{Line: 2, Column: 5}, position{Line: 1, Character: 0}},
+		{"line beyond src", src, token.Position{Line: 9, Column: 3}, position{Line: 8, Character: 2}},
+		{"nil src", nil, token.Position{Line: 3, Column: 12}, position{Line: 2, Character: 11}},
+		{"unknown position", src, token.Position{}, position{}},
+		{"unknown column", src, token.Position{Line: 3}, position{Line: 2, Character: 0}},
+	}
+	for _, tt := range tests {
+		if got := lspPosition(tt.src, tt.pos); got != tt.want {
+			t.Errorf("%s: lspPosition(%v) = %+v, want %+v", tt.name, tt.pos, got, tt.want)
+		}
+	}
+}
+
+type errWriter struct{ err error }
+
+func (w errWriter) Write(p []byte) (int, error) { return 0, w.err }
+
+func TestServePublishError(t *testing.T) {
+	body := `{"jsonrpc":"2.0","method":"textDocument/didClose","params":{"textDocument":{"uri":"file:///tmp/a.go"}}}`
+	in := strings.NewReader(fmt.Sprintf("Content-Length: %d\r\n\r\n%s", len(body), body))
+	werr := errors.New("broken pipe")
+
+	s := NewServer(nil)
+	if err := s.Serve(in, errWriter{werr}); err != werr {
+		t.Errorf("Serve() = %v, want %v", err, werr)
+	}
+}
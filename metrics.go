@@ -0,0 +1,161 @@
+// Copyright 2017 The go-importlint Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package importlint
+
+import (
+	"go/ast"
+	"go/build"
+	"go/token"
+	"math"
+	"sort"
+)
+
+// Metrics is Robert Martin's coupling metrics of a package or a group of packages
+// such as a layer.
+type Metrics struct {
+	// Name is the import path of the package or the name of the group.
+	Name string
+	// Packages is the number of the packages in the group.
+	Packages int
+	// Afferent is the number of the packages outside the group which import the group (Ca).
+	Afferent int
+	// Efferent is the number of the packages outside the group which the group imports (Ce).
+	Efferent int
+	// Types is the number of the types declared in the group, and Interfaces is
+	// the number of the interface types of those.
+	Types      int
+	Interfaces int
+}
+
+// Instability returns Ce / (Ca + Ce). ok is false if the group has no couplings,
+// where the instability is undefined.
+func (m Metrics) Instability() (i float64, ok bool) {
+	if m.Afferent+m.Efferent == 0 {
+		return 0, false
+	}
+	return float64(m.Efferent) / float64(m.Afferent+m.Efferent), true
+}
+
+// Abstractness returns the ratio of the interface types to all types, or 0 if the
+// group has no types.
+func (m Metrics) Abstractness() float64 {
+	if m.Types == 0 {
+		return 0
+	}
+	return float64(m.Interfaces) / float64(m.Types)
+}
+
+// Distance returns the distance from the main sequence |A + I - 1|. ok is false
+// if the instability is undefined.
+func (m Metrics) Distance() (d float64, ok bool) {
+	i, ok := m.Instability()
+	if !ok {
+		return 0, false
+	}
+	return math.Abs(m.Abstractness() + i - 1), true
+}
+
+// Metrics returns the metrics of the groups of the project packages of g by key,
+// sorted by the name. The packages whose key is empty are not grouped, but count
+// as the couplings of the other groups.
+// The couplings are counted within the project packages, and the types are
+// counted from the Go files of each package except the tests.
+//
+// The problems of parsing the packages are returned as the Diagnostics error with
+// the metrics.
+func (bc *BuildContext) Metrics(fset *token.FileSet, g *Graph, key func(*Node) string) ([]Metrics, error) {
+	var diags Diagnostics
+	groups := make(map[string]*Metrics)
+	keys := make(map[string]string)
+	for _, path := range g.sortedNodes() {
+		n := g.Nodes[path]
+		if n.Class != ProjectImport {
+			continue
+		}
+		k := key(n)
+		keys[path] = k
+		if k == "" {
+			continue
+		}
+		m, ok := groups[k]
+		if !ok {
+			m = &Metrics{Name: k}
+			groups[k] = m
+		}
+		m.Packages++
+
+		if n.Dir == "" {
+			continue
+		}
+		types, ifaces, err := bc.countTypes(fset, n.Dir)
+		diags = append(diags, DiagnosticsOf(err)...)
+		m.Types += types
+		m.Interfaces += ifaces
+	}
+
+	afferent := make(map[string]map[string]bool)
+	efferent := make(map[string]map[string]bool)
+	add := func(sets map[string]map[string]bool, k, path string) {
+		if sets[k] == nil {
+			sets[k] = make(map[string]bool)
+		}
+		sets[k][path] = true
+	}
+	for _, e := range g.Edges() {
+		from, fok := keys[e.From]
+		to, tok := keys[e.To]
+		if !fok || !tok || from == to {
+			continue
+		}
+		if from != "" {
+			add(efferent, from, e.To)
+		}
+		if to != "" {
+			add(afferent, to, e.From)
+		}
+	}
+
+	res := make([]Metrics, 0, len(groups))
+	for k, m := range groups {
+		m.Afferent = len(afferent[k])
+		m.Efferent = len(efferent[k])
+		res = append(res, *m)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Name < res[j].Name })
+
+	diags.Sort()
+	return res, diags.Err()
+}
+
+// countTypes returns the number of the types and the interface types declared in
+// the package of dir.
+func (bc *BuildContext) countTypes(fset *token.FileSet, dir string) (types, interfaces int, err error) {
+	pkg, err := bc.ctxt.ImportDir(dir, build.ImportMode(0))
+	if err != nil {
+		if _, ok := err.(*build.NoGoError); ok {
+			return 0, 0, nil
+		}
+		return 0, 0, importDiagnostics(dir, err)
+	}
+
+	pkgs, err := ParseDir(fset, bc, dir, GoFilesFilter(pkg, false), 0)
+	for _, p := range pkgs {
+		for _, file := range p.Files {
+			for _, decl := range file.Decls {
+				gen, ok := decl.(*ast.GenDecl)
+				if !ok || gen.Tok != token.TYPE {
+					continue
+				}
+				for _, spec := range gen.Specs {
+					types++
+					if _, ok := spec.(*ast.TypeSpec).Type.(*ast.InterfaceType); ok {
+						interfaces++
+					}
+				}
+			}
+		}
+	}
+	return types, interfaces, err
+}
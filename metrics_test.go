@@ -0,0 +1,35 @@
+// Copyright 2017 The go-importlint Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package importlint
+
+import "testing"
+
+func TestMetrics(t *testing.T) {
+	tests := []struct {
+		m            Metrics
+		wantI, wantD float64
+		wantOK       bool
+		wantA        float64
+	}{
+		{Metrics{Afferent: 1, Efferent: 3, Types: 4, Interfaces: 1}, 0.75, 0, true, 0.25},
+		{Metrics{Afferent: 2}, 0, 1, true, 0},
+		{Metrics{Efferent: 2, Types: 2, Interfaces: 2}, 1, 1, true, 1},
+		// the instability is undefined without couplings.
+		{Metrics{Types: 2, Interfaces: 1}, 0, 0, false, 0.5},
+	}
+	for _, tt := range tests {
+		i, ok := tt.m.Instability()
+		if i != tt.wantI || ok != tt.wantOK {
+			t.Errorf("%+v: Instability() = %v, %v, want %v, %v", tt.m, i, ok, tt.wantI, tt.wantOK)
+		}
+		d, ok := tt.m.Distance()
+		if d != tt.wantD || ok != tt.wantOK {
+			t.Errorf("%+v: Distance() = %v, %v, want %v, %v", tt.m, d, ok, tt.wantD, tt.wantOK)
+		}
+		if a := tt.m.Abstractness(); a != tt.wantA {
+			t.Errorf("%+v: Abstractness() = %v, want %v", tt.m, a, tt.wantA)
+		}
+	}
+}
@@ -0,0 +1,370 @@
+// Copyright 2017 The go-importlint Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package importlint
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// MigrateConfig rewrites the config src of the old schema version into the latest version.
+// The format is chosen by ext the same as ParseConfig.
+//
+// The YAML and TOML configs are rewritten line by line to preserve the comments.
+// The src of the latest version is returned as is.
+func MigrateConfig(src []byte, ext string) ([]byte, error) {
+	switch ext {
+	case ".toml":
+		return migrateTOML(src)
+	case ".json":
+		return migrateJSON(src)
+	}
+	return migrateYAML(src)
+}
+
+func migrateJSON(src []byte) ([]byte, error) {
+	conf := new(Config)
+	if err := json.Unmarshal(src, conf); err != nil {
+		return nil, errors.Wrap(err, "could not parse JSON config")
+	}
+	if conf.Version == ConfigVersion {
+		return src, nil
+	}
+	if err := conf.Upgrade(); err != nil {
+		return nil, err
+	}
+
+	buf, err := json.MarshalIndent(conf, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(buf, '\n'), nil
+}
+
+// configLine is a line of the YAML or TOML config.
+type configLine struct {
+	text    string
+	indent  int
+	key     string // key of the "key: value" or "key = value" line
+	value   string // value without the trailing comment
+	comment string // trailing comment including "#"
+}
+
+func (l configLine) isBlank() bool   { return strings.TrimSpace(l.text) == "" }
+func (l configLine) isComment() bool { return strings.HasPrefix(strings.TrimSpace(l.text), "#") }
+
+// splitConfigLine splits line into configLine by the sep of key and value.
+func splitConfigLine(line, sep string) configLine {
+	l := configLine{text: line}
+	trimmed := strings.TrimLeft(line, " \t")
+	l.indent = len(line) - len(trimmed)
+	if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+		return l
+	}
+
+	body := trimmed
+	if i := commentIndex(trimmed); i >= 0 {
+		body, l.comment = strings.TrimRight(trimmed[:i], " \t"), trimmed[i:]
+	}
+	if i := strings.Index(body, sep); i >= 0 && !strings.HasPrefix(body, "-") && !strings.HasPrefix(body, "[") {
+		l.key = strings.TrimSpace(body[:i])
+		l.value = strings.TrimSpace(body[i+len(sep):])
+	} else {
+		l.value = body
+	}
+	return l
+}
+
+// commentIndex returns the index of "#" which starts the comment outside of the quotes.
+func commentIndex(s string) int {
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#' && (i == 0 || s[i-1] == ' ' || s[i-1] == '\t'):
+			return i
+		}
+	}
+	return -1
+}
+
+func withComment(s, comment string) string {
+	if comment == "" {
+		return s
+	}
+	return s + " " + comment
+}
+
+func splitLines(src []byte) []string {
+	s := strings.TrimSuffix(string(src), "\n")
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
+
+func joinLines(lines []string) []byte {
+	return []byte(strings.Join(lines, "\n") + "\n")
+}
+
+// configVersion returns the version written in lines of the top level, and whether
+// lines has the version. The top level ends at the first TOML table header.
+func configVersion(lines []configLine) (int, bool, error) {
+	for _, l := range lines {
+		if l.indent != 0 {
+			continue
+		}
+		if l.key == "" && strings.HasPrefix(l.value, "[") {
+			break
+		}
+		if l.key == "version" {
+			v, err := strconv.Atoi(strings.Trim(l.value, `"'`))
+			if err != nil {
+				return 0, false, errors.Errorf("invalid config version %q", l.value)
+			}
+			return v, true, nil
+		}
+	}
+	return 1, false, nil
+}
+
+func migrateYAML(src []byte) ([]byte, error) {
+	var lines []configLine
+	for _, line := range splitLines(src) {
+		lines = append(lines, splitConfigLine(line, ":"))
+	}
+
+	version, hasVersion, err := configVersion(lines)
+	if err != nil {
+		return nil, err
+	}
+	switch version {
+	case ConfigVersion:
+		return src, nil
+	case 1:
+	default:
+		return nil, errors.Errorf("unsupported config version %d", version)
+	}
+
+	var (
+		out         []string
+		inLayer     bool
+		inSeq       bool // in the compact sequence of the allowed layers at the layer key indent
+		layerIndent = -1
+		pending     = -1 // index in out of the layer line which the allow line is not written yet
+		pendingLine configLine
+		commentAt   = -1 // index in out of the first comment line in the pending layer
+	)
+	// flush rewrites the pending layer line which has no allowed layers.
+	flush := func() {
+		if pending >= 0 {
+			out[pending] = withComment(strings.Repeat(" ", pendingLine.indent)+pendingLine.key+": {}", pendingLine.comment)
+			pending = -1
+		}
+		inSeq, commentAt = false, -1
+	}
+	// writeAllow writes the allow line of the pending layer before its comment lines.
+	writeAllow := func() {
+		at := len(out)
+		if commentAt >= 0 {
+			at = commentAt
+		}
+		allow := strings.Repeat(" ", pendingLine.indent) + "  allow:"
+		out = append(out[:at], append([]string{allow}, out[at:]...)...)
+		pending, commentAt = -1, -1
+	}
+
+	for _, l := range lines {
+		if l.indent == 0 && strings.TrimSpace(l.text) == "---" {
+			out = append(out, l.text) // the version is written in the document
+			continue
+		}
+		if !hasVersion && !l.isBlank() && !l.isComment() {
+			out = append(out, fmt.Sprintf("version: %d", ConfigVersion))
+			hasVersion = true
+		}
+
+		if l.indent == 0 && !l.isBlank() && !l.isComment() {
+			flush()
+			inLayer = false
+			switch l.key {
+			case "version":
+				out = append(out, withComment(fmt.Sprintf("version: %d", ConfigVersion), l.comment))
+				continue
+			case "layer":
+				switch l.value {
+				case "":
+				case "{}", "~", "null":
+					out = append(out, withComment("layers: {}", l.comment))
+					continue
+				default:
+					return nil, errors.New("could not migrate the flow style layer")
+				}
+				out = append(out, withComment("layers:", l.comment))
+				inLayer = true
+				layerIndent = -1
+				continue
+			}
+			out = append(out, l.text)
+			continue
+		}
+
+		if !inLayer || l.isBlank() {
+			out = append(out, l.text)
+			continue
+		}
+		if l.isComment() {
+			if pending >= 0 && commentAt < 0 {
+				commentAt = len(out)
+			}
+			if layerIndent >= 0 && l.indent > layerIndent {
+				out = append(out, "  "+l.text)
+			} else {
+				out = append(out, l.text)
+			}
+			continue
+		}
+
+		if layerIndent < 0 {
+			layerIndent = l.indent
+		}
+		indent := strings.Repeat(" ", l.indent)
+		switch {
+		case l.indent == layerIndent && l.key == "" && strings.HasPrefix(l.value, "-"):
+			// The compact sequence of the allowed layers at the same indent as the layer key.
+			if pending >= 0 {
+				writeAllow()
+				inSeq = true
+			}
+			if !inSeq {
+				return nil, errors.Errorf("could not migrate the sequence item %q out of a layer", l.value)
+			}
+			out = append(out, "  "+l.text)
+		case l.indent == layerIndent:
+			flush()
+			if l.key == "" {
+				return nil, errors.Errorf("could not migrate the layer line %q", l.value)
+			}
+			switch l.value {
+			case "", "~", "null":
+				out = append(out, withComment(indent+l.key+":", l.comment))
+				pending, pendingLine = len(out)-1, l
+			case "[]":
+				out = append(out, withComment(indent+l.key+": {}", l.comment))
+			default:
+				out = append(out, withComment(indent+l.key+":", l.comment))
+				out = append(out, indent+"  allow: "+l.value)
+			}
+		case l.indent > layerIndent:
+			if pending >= 0 {
+				if !strings.HasPrefix(l.value, "-") {
+					return nil, errors.Errorf("could not migrate the layer %s which is not a list", pendingLine.key)
+				}
+				writeAllow()
+			}
+			out = append(out, "  "+l.text)
+		default:
+			return nil, errors.Errorf("could not migrate the layer line %q indented less than the other layers", strings.TrimSpace(l.text))
+		}
+	}
+	flush()
+
+	return joinLines(out), nil
+}
+
+func migrateTOML(src []byte) ([]byte, error) {
+	var lines []configLine
+	for _, line := range splitLines(src) {
+		lines = append(lines, splitConfigLine(line, "="))
+	}
+
+	version, hasVersion, err := configVersion(lines)
+	if err != nil {
+		return nil, err
+	}
+	switch version {
+	case ConfigVersion:
+		return src, nil
+	case 1:
+	default:
+		return nil, errors.Errorf("unsupported config version %d", version)
+	}
+
+	var (
+		out      []string
+		inLayer  bool
+		array    []string // the lines of multiline array value of the layer
+		arrayKey configLine
+	)
+	writeLayer := func(l configLine, value string) {
+		out = append(out, fmt.Sprintf("[layers.%s]", l.key))
+		if value != "[]" {
+			out = append(out, withComment("allow = "+value, l.comment))
+		}
+	}
+
+	for _, l := range lines {
+		if array != nil {
+			array = append(array, l.text)
+			if strings.Contains(l.value, "]") {
+				writeLayer(arrayKey, strings.Join(array, "\n"))
+				array = nil
+			}
+			continue
+		}
+
+		if !hasVersion && !l.isBlank() && !l.isComment() {
+			out = append(out, fmt.Sprintf("version = %d", ConfigVersion))
+			hasVersion = true
+		}
+		if l.isBlank() || l.isComment() {
+			out = append(out, l.text)
+			continue
+		}
+
+		if l.key == "" && strings.HasPrefix(l.value, "[") {
+			inLayer = l.value == "[layer]"
+			if inLayer {
+				continue // replaced by [layers.<layer>] tables
+			}
+			if strings.HasPrefix(l.value, "[layer.") {
+				return nil, errors.Errorf("could not migrate the %s table", l.value)
+			}
+			out = append(out, l.text)
+			continue
+		}
+
+		switch {
+		case !inLayer && l.key == "version":
+			out = append(out, withComment(fmt.Sprintf("version = %d", ConfigVersion), l.comment))
+		case !inLayer && l.key == "layer":
+			return nil, errors.New("could not migrate the inline layer table")
+		case inLayer:
+			if strings.HasPrefix(l.value, "[") && !strings.Contains(l.value, "]") {
+				array = []string{withComment(l.value, l.comment)}
+				arrayKey = l
+				arrayKey.comment = ""
+				continue
+			}
+			writeLayer(l, l.value)
+		default:
+			out = append(out, l.text)
+		}
+	}
+	if array != nil {
+		return nil, errors.Errorf("unterminated array of layer %s", arrayKey.key)
+	}
+
+	return joinLines(out), nil
+}
@@ -0,0 +1,336 @@
+// Copyright 2017 The go-importlint Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package importlint
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v2"
+)
+
+func TestMigrateConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		ext  string
+		src  string
+		want string
+	}{
+		{
+			name: "yaml block lists",
+			ext:  ".yaml",
+			src: `project: app
+layer:
+  domain:
+  application:
+    - domain
+  infrastructure:
+    - domain
+    - application
+`,
+			want: `version: 2
+project: app
+layers:
+  domain: {}
+  application:
+    allow:
+      - domain
+  infrastructure:
+    allow:
+      - domain
+      - application
+`,
+		},
+		{
+			name: "yaml compact lists",
+			ext:  ".yaml",
+			src: `layer:
+  interfaces:
+  - application
+  - domain
+  application:
+  - domain
+  domain:
+project: app
+`,
+			want: `version: 2
+layers:
+  interfaces:
+    allow:
+    - application
+    - domain
+  application:
+    allow:
+    - domain
+  domain: {}
+project: app
+`,
+		},
+		{
+			name: "yaml flow lists",
+			ext:  ".yaml",
+			src: `layer:
+  domain: []
+  application: [domain]
+  infrastructure: [domain,
+    application]
+`,
+			want: `version: 2
+layers:
+  domain: {}
+  application:
+    allow: [domain]
+  infrastructure:
+    allow: [domain,
+      application]
+`,
+		},
+		{
+			name: "yaml empty values",
+			ext:  ".yaml",
+			src: `layer:
+  a: ~
+  b: null
+  c: []
+  d:
+`,
+			want: `version: 2
+layers:
+  a: {}
+  b: {}
+  c: {}
+  d: {}
+`,
+		},
+		{
+			name: "yaml empty layer",
+			ext:  ".yaml",
+			src:  "project: app\nlayer: {} # nothing\n",
+			want: "version: 2\nproject: app\nlayers: {} # nothing\n",
+		},
+		{
+			name: "yaml comments",
+			ext:  ".yaml",
+			src: `# header
+
+project: app # the project
+layer: # the layers
+  # the domain layer
+  domain: # no imports
+  application: [domain] # flow
+  infrastructure:
+    # the allowed layers
+    - domain # the domain
+    # and
+    - application
+  # the last layer
+  interfaces: ~ # null
+# footer
+`,
+			want: `# header
+
+version: 2
+project: app # the project
+layers: # the layers
+  # the domain layer
+  domain: {} # no imports
+  application: # flow
+    allow: [domain]
+  infrastructure:
+    allow:
+      # the allowed layers
+      - domain # the domain
+      # and
+      - application
+  # the last layer
+  interfaces: {} # null
+# footer
+`,
+		},
+		{
+			name: "yaml version 1",
+			ext:  ".yml",
+			src: `project: app
+version: 1 # the old version
+layer:
+  domain: []
+`,
+			want: `project: app
+version: 2 # the old version
+layers:
+  domain: {}
+`,
+		},
+		{
+			name: "yaml document marker",
+			ext:  ".yaml",
+			src:  "---\nlayer:\n  domain: []\n",
+			want: "---\nversion: 2\nlayers:\n  domain: {}\n",
+		},
+		{
+			name: "yaml latest version",
+			ext:  ".yaml",
+			src:  "version: 2\nlayers:\n  domain: {} # as is\n",
+			want: "version: 2\nlayers:\n  domain: {} # as is\n",
+		},
+		{
+			name: "toml",
+			ext:  ".toml",
+			src: `# header
+project = "app"
+
+[layer]
+# the domain layer
+domain = []
+application = ["domain"] # flow
+infrastructure = [
+  "domain", # the domain
+  # and
+  "application",
+]
+
+[other]
+key = "value"
+`,
+			want: `# header
+version = 2
+project = "app"
+
+# the domain layer
+[layers.domain]
+[layers.application]
+allow = ["domain"] # flow
+[layers.infrastructure]
+allow = [
+  "domain", # the domain
+  # and
+  "application",
+]
+
+[other]
+key = "value"
+`,
+		},
+		{
+			name: "toml version 1",
+			ext:  ".toml",
+			src:  "version = 1 # the old version\n\n[layer]\ndomain = []\n",
+			want: "version = 2 # the old version\n\n[layers.domain]\n",
+		},
+		{
+			name: "toml layer named version",
+			ext:  ".toml",
+			src:  "[layer]\nversion = []\n",
+			want: "version = 2\n[layers.version]\n",
+		},
+		{
+			name: "toml latest version",
+			ext:  ".toml",
+			src:  "version = 2\n\n[layers.domain]\n",
+			want: "version = 2\n\n[layers.domain]\n",
+		},
+		{
+			name: "json",
+			ext:  ".json",
+			src:  `{"project": "app", "layer": {"application": ["domain"]}}`,
+			want: `{
+  "version": 2,
+  "project": "app",
+  "layers": {
+    "application": {
+      "allow": [
+        "domain"
+      ]
+    }
+  }
+}
+`,
+		},
+	}
+	for _, tt := range tests {
+		got, err := MigrateConfig([]byte(tt.src), tt.ext)
+		if err != nil {
+			t.Errorf("%s: MigrateConfig() error = %v", tt.name, err)
+			continue
+		}
+		if string(got) != tt.want {
+			t.Errorf("%s: MigrateConfig() =\n%s\nwant\n%s", tt.name, got, tt.want)
+			continue
+		}
+
+		// the migrated config must be the same config as the source.
+		want, err := unmarshalConfig([]byte(tt.src), tt.ext)
+		if err != nil {
+			t.Errorf("%s: could not parse source: %v", tt.name, err)
+			continue
+		}
+		conf, err := unmarshalConfig(got, tt.ext)
+		if err != nil {
+			t.Errorf("%s: could not parse migrated config: %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(conf.Layers, want.Layers) || conf.Project != want.Project {
+			t.Errorf("%s: migrated config = %+v, want %+v", tt.name, conf, want)
+		}
+	}
+}
+
+func TestMigrateConfigError(t *testing.T) {
+	tests := []struct {
+		name string
+		ext  string
+		src  string
+		want string
+	}{
+		{"yaml unsupported version", ".yaml", "version: 3\n", "unsupported config version 3"},
+		{"yaml invalid version", ".yaml", "version: x\n", "invalid config version"},
+		{"yaml flow layer", ".yaml", "layer: {domain: []}\n", "flow style layer"},
+		{"yaml sequence out of layer", ".yaml", "layer:\n  domain: [a]\n  - b\n", "out of a layer"},
+		{"yaml mapping layer", ".yaml", "layer:\n  domain:\n    allow: [a]\n", "not a list"},
+		{"yaml less indented layer", ".yaml", "layer:\n    domain: []\n  application: []\n", "indented less"},
+		{"toml unsupported version", ".toml", "version = 3\n", "unsupported config version 3"},
+		{"toml layer table", ".toml", "[layer.domain]\nallow = []\n", "could not migrate the [layer.domain] table"},
+		{"toml inline layer", ".toml", "layer = {}\n", "inline layer table"},
+		{"toml unterminated array", ".toml", "[layer]\ndomain = [\n  \"a\",\n", "unterminated array of layer domain"},
+		{"json syntax", ".json", "{", "could not parse JSON config"},
+		{"json layers in version 1", ".json", `{"version": 1, "layers": {}}`, "layers is not allowed"},
+	}
+	for _, tt := range tests {
+		got, err := MigrateConfig([]byte(tt.src), tt.ext)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: MigrateConfig() = %q, %v, want error %q", tt.name, got, err, tt.want)
+		}
+	}
+}
+
+func unmarshalConfig(src []byte, ext string) (*Config, error) {
+	conf := new(Config)
+	var err error
+	switch ext {
+	case ".toml":
+		err = toml.Unmarshal(src, conf)
+	case ".json":
+		err = json.Unmarshal(src, conf)
+	default:
+		err = yaml.Unmarshal(src, conf)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := conf.Upgrade(); err != nil {
+		return nil, err
+	}
+	// the empty list and no list are the same.
+	for layer, lc := range conf.Layers {
+		if len(lc.Allow) == 0 {
+			lc.Allow = nil
+		}
+		conf.Layers[layer] = lc
+	}
+	return conf, nil
+}
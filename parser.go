@@ -9,65 +9,140 @@ import (
 	"go/ast"
 	"go/build"
 	"go/parser"
+	"go/scanner"
 	"go/token"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"golang.org/x/tools/go/buildutil"
 )
 
+// FindMode controls the behavior of FindAllPackage.
 type FindMode int
 
 const (
+	// ExcludeVendor skips the vendor directory trees.
 	ExcludeVendor FindMode = 1 << iota
+	// AllGOPATH walks the src directory of all GOPATH entries instead of the root directory.
+	AllGOPATH
 )
 
-// FindAllPackage returns a list of all packages in all of the GOPATH trees
-// in the given build context. If prefix is non-empty, only packages
-// whose import paths begin with prefix are returned.
+// FindAllPackage returns a list of all packages in the root directory of bc.
+// If mode has AllGOPATH, returns the packages in all of the GOPATH trees.
+// The packages are deduplicated by the import path, and the first GOPATH entry wins
+// the same as the go tool.
 func (bc *BuildContext) FindAllPackage(ignores []string, mode FindMode) ([]*build.Package, error) {
+	roots := []string{bc.root}
+	if mode&AllGOPATH != 0 {
+		roots = roots[:0]
+		for _, gopath := range bc.gopaths {
+			roots = append(roots, srcDir(gopath))
+		}
+	}
+	return bc.FindAllPackageIn(roots, ignores, mode)
+}
+
+// FindAllPackageIn returns a list of all packages in the roots directories.
+//
+// The root in a module is walked through the whole module tree except the nested
+// modules, and the import paths are derived from the module path. The other roots
+// are walked through the directories in the src directory of GOPATH entries.
+//
+// The problems of walking the directory trees and loading the packages do not
+// stop finding, and are returned as the Diagnostics error with the found packages.
+func (bc *BuildContext) FindAllPackageIn(roots []string, ignores []string, mode FindMode) ([]*build.Package, error) {
 	pkgs := []*build.Package{}
 	done := make(map[string]bool)
+	seen := make(map[string]bool) // import paths
+	var diags Diagnostics
 
-	filepath.Walk(bc.root, func(path string, fi os.FileInfo, err error) error {
-		if err != nil || !fi.IsDir() {
-			return nil
+	for _, root := range roots {
+		root = filepath.Clean(root)
+		var modPath, modDir string
+		if mode&AllGOPATH == 0 {
+			modPath, modDir = findGoMod(root)
 		}
+		filepath.Walk(root, func(path string, fi os.FileInfo, err error) error {
+			if err != nil {
+				diags = append(diags, Diagnostic{Kind: WalkError, Pos: token.Position{Filename: path}, Message: err.Error()})
+				return nil
+			}
+			if !fi.IsDir() {
+				return nil
+			}
 
-		// avoid .foo, _foo, and testdata directory trees.
-		_, elem := filepath.Split(path)
-		if elem == "pkg" || strings.HasPrefix(elem, ".") || strings.HasPrefix(elem, "_") || elem == "testdata" || (mode&ExcludeVendor != 0 && elem == "vendor") || matchIgnore(elem, ignores) {
-			return filepath.SkipDir
-		}
+			// avoid .foo, _foo, and testdata directory trees.
+			_, elem := filepath.Split(path)
+			if elem == "pkg" || strings.HasPrefix(elem, ".") || strings.HasPrefix(elem, "_") || elem == "testdata" || (mode&ExcludeVendor != 0 && elem == "vendor") || matchIgnore(elem, ignores) {
+				return filepath.SkipDir
+			}
 
-		name := filepath.ToSlash(path[len(bc.root):])
-		if done[name] {
-			return nil
-		}
-		done[name] = true
-
-		if path != bc.root {
-			// TODO(zchee): O(n)
-			for _, gopath := range bc.gopaths {
-				// check contains path in "src" directory
-				if strings.Contains(path, srcDir(gopath)) {
-					break
-				}
+			if done[path] {
 				return filepath.SkipDir
 			}
-		}
+			done[path] = true
+
+			switch {
+			case path == root:
+			case modPath != "":
+				if !isNotExist(filepath.Join(path, "go.mod")) {
+					return filepath.SkipDir // the nested module
+				}
+			default:
+				inSrc, aboveSrc := bc.inGOPATH(path)
+				if !inSrc && !aboveSrc {
+					return filepath.SkipDir
+				}
+				if !inSrc {
+					return nil // descend to the src directory
+				}
+			}
+
+			pkg, err := bc.ctxt.ImportDir(path, build.ImportMode(0))
+			if err != nil {
+				if _, ok := err.(*build.NoGoError); ok {
+					return nil
+				}
+				diags = append(diags, importDiagnostics(path, err)...)
+			}
+			if modPath != "" {
+				pkg.ImportPath = moduleImportPath(modPath, modDir, path)
+			}
+			if pkg.ImportPath != "" && pkg.ImportPath != "." {
+				if seen[pkg.ImportPath] {
+					return nil
+				}
+				seen[pkg.ImportPath] = true
+			}
+			pkgs = append(pkgs, pkg)
 
-		pkg, err := bc.ctxt.ImportDir(path, build.ImportMode(0))
-		if err != nil && strings.Contains(err.Error(), "no buildable Go source files") {
 			return nil
-		}
-		pkgs = append(pkgs, pkg)
+		})
+	}
 
-		return nil
-	})
+	diags.Sort()
+	return pkgs, diags.Err()
+}
 
-	return pkgs, nil
+// inGOPATH reports whether the dir is in the src directory of any GOPATH entries,
+// or dir is an ancestor of that.
+func (bc *BuildContext) inGOPATH(dir string) (inSrc, aboveSrc bool) {
+	for _, gopath := range bc.gopaths {
+		src := srcDir(gopath)
+		if filepath.Clean(dir) == src {
+			aboveSrc = true
+			continue
+		}
+		if _, ok := buildutil.HasSubdir(bc.ctxt, src, dir); ok {
+			return true, false
+		}
+		if _, ok := buildutil.HasSubdir(bc.ctxt, dir, src); ok {
+			aboveSrc = true
+		}
+	}
+	return false, aboveSrc
 }
 
 func matchIgnore(elem string, ignores []string) bool {
@@ -79,21 +154,37 @@ func matchIgnore(elem string, ignores []string) bool {
 	return false
 }
 
-// ParseDir wrapper of buildutil.ParseFile with BuildContext.
-func ParseDir(fset *token.FileSet, bctx *BuildContext, path string, filter func(os.FileInfo) bool, mode parser.Mode) (map[string]*ast.Package, error) {
-	fd, err := os.Open(path)
-	if err != nil {
-		return nil, err
+// GoFilesFilter returns the ParseDir filter which matches the Go files of pkg
+// selected by the build constraints. If tests is true, the test files of both the
+// package and the external test package are matched too.
+func GoFilesFilter(pkg *build.Package, tests bool) func(os.FileInfo) bool {
+	lists := [][]string{pkg.GoFiles, pkg.CgoFiles}
+	if tests {
+		lists = append(lists, pkg.TestGoFiles, pkg.XTestGoFiles)
 	}
-	defer fd.Close()
+	files := make(map[string]bool)
+	for _, list := range lists {
+		for _, name := range list {
+			files[name] = true
+		}
+	}
+	return func(fi os.FileInfo) bool {
+		return files[fi.Name()]
+	}
+}
 
-	list, err := fd.Readdir(-1)
+// ParseDir wrapper of buildutil.ParseFile with BuildContext.
+//
+// The files which could not be parsed are skipped, and all of those errors are
+// returned as the Diagnostics error with the parsed packages.
+func ParseDir(fset *token.FileSet, bctx *BuildContext, path string, filter func(os.FileInfo) bool, mode parser.Mode) (map[string]*ast.Package, error) {
+	list, err := buildutil.ReadDir(bctx.ctxt, path)
 	if err != nil {
 		return nil, err
 	}
 
 	pkgs := make(map[string]*ast.Package)
-	var firstErr error
+	var diags Diagnostics
 	for _, d := range list {
 		if strings.HasSuffix(d.Name(), ".go") && (filter == nil || filter(d)) {
 			filename := filepath.Join(path, d.Name())
@@ -108,27 +199,117 @@ func ParseDir(fset *token.FileSet, bctx *BuildContext, path string, filter func(
 					pkgs[name] = pkg
 				}
 				pkg.Files[filename] = src
-			} else if firstErr == nil {
-				firstErr = err
+			} else if list, ok := err.(scanner.ErrorList); ok {
+				diags = append(diags, parseDiagnostics(list)...)
+			} else {
+				diags = append(diags, Diagnostic{Kind: ParseError, Pos: token.Position{Filename: filename}, Message: err.Error()})
 			}
 		}
 	}
 
-	return pkgs, firstErr
+	diags.Sort()
+	return pkgs, diags.Err()
 }
 
-func CheckDependency(pkgs map[string]*ast.Package, conf *Config) {
-	importmap := make(map[string][]string)
+// CheckDependency checks the imports of pkgs according to the Layers config.
+// The layer of each package is matched by the import path with the Packages
+// patterns, or by the package name. The external test package has the layer of
+// the package under test.
+//
+// If bctx is non-nil, each import is resolved by bctx following the vendor
+// lookup, and the layer of the import is determined by the resolved import path.
+// Also the project import path is inferred by bctx if conf.Project is empty, and
+// the imports of the other projects are not checked by the layer unless the Packages
+// pattern matches the resolved import path, such as "vendor/github.com/foo/bar/...".
+//
+// The imports which could not be resolved by bctx are not checked by the layer,
+// and are returned as the Diagnostics error of UnresolvedImport with the violations.
+func CheckDependency(fset *token.FileSet, bctx *BuildContext, pkgs map[string]*ast.Package, conf *Config) ([]Violation, error) {
+	var (
+		violations []Violation
+		diags      Diagnostics
+		project    = conf.Project
+		projectDir string
+	)
+	if bctx != nil {
+		project, projectDir = bctx.Project(conf)
+		if project != conf.Project {
+			c := *conf
+			c.Project = project
+			conf = &c
+		}
+	}
 
 	for name, obj := range pkgs {
-		fmt.Printf("name: %+v\n", name)
-		fmt.Printf("obj.Name: %+v\n", obj.Name)
-		if conf.Layer[name] != nil {
-			for id, file := range obj.Files {
-				for _, imppkg := range file.Imports {
-					importmap[id] = append(importmap[id], imppkg.Path.Value)
+		var pkgPath string
+		if bctx != nil {
+			for filename := range obj.Files {
+				pkgPath = bctx.importPathOf(filepath.Dir(filename), project, projectDir)
+				break
+			}
+		}
+		layer, hasLayer := conf.PackageLayer(pkgPath, strings.TrimSuffix(name, "_test"))
+		if !hasLayer && bctx == nil {
+			continue
+		}
+
+		for filename, file := range obj.Files {
+			for _, imppkg := range file.Imports {
+				path, err := strconv.Unquote(imppkg.Path.Value)
+				if err != nil {
+					continue
+				}
+
+				resolved, dir := path, ""
+				class := ProjectImport
+				if bctx != nil {
+					if path == "C" {
+						continue // cgo
+					}
+					r, d, searched, ok := bctx.findImport(path, filepath.Dir(filename))
+					if !ok {
+						diags = append(diags, Diagnostic{
+							Kind:     UnresolvedImport,
+							Pos:      fset.Position(imppkg.Pos()),
+							Message:  fmt.Sprintf("could not resolve import %q", path),
+							Searched: searched,
+						})
+						continue
+					}
+					if !hasLayer {
+						continue
+					}
+					resolved, dir = r, d
+					class = bctx.classify(project, path, filepath.Dir(filename))
+					if class != ProjectImport {
+						// The other packages such as the vendored ones are checked
+						// only if the Packages pattern matches the resolved path.
+						if _, ok := conf.matchLayer(resolved); !ok {
+							continue
+						}
+					}
 				}
+
+				implayer, ok := conf.LayerOf(resolved)
+				if !ok || conf.CanImport(layer, implayer) {
+					continue
+				}
+				violations = append(violations, Violation{
+					Pos:         fset.Position(imppkg.Pos()),
+					End:         fset.Position(imppkg.End()),
+					Layer:       layer,
+					Import:      path,
+					ImportLayer: implayer,
+					Class:       class,
+					Resolved:    resolved,
+					Dir:         dir,
+				})
 			}
 		}
 	}
+
+	sortViolations(violations)
+	diags.Sort()
+
+	return violations, diags.Err()
 }
@@ -0,0 +1,111 @@
+// Copyright 2017 The go-importlint Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package importlint
+
+import (
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestFindAllPackageModule(t *testing.T) {
+	root, err := filepath.Abs(filepath.Join("testdata", "module"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	bc := NewBuildContext(root, WithGOROOT(""), WithGOPATH(filepath.Join(root, "not-exist")))
+	pkgs, err := bc.FindAllPackage(nil, ExcludeVendor)
+	if err != nil {
+		t.Fatalf("FindAllPackage() error = %v", err)
+	}
+
+	var got []string
+	for _, pkg := range pkgs {
+		got = append(got, pkg.ImportPath)
+	}
+	sort.Strings(got)
+	// the nested module is not a part of the module.
+	want := []string{"example.com/shop/domain", "example.com/shop/infrastructure"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FindAllPackage() = %v, want %v", got, want)
+	}
+}
+
+func TestCheckDependencyVendored(t *testing.T) {
+	module, err := filepath.Abs(filepath.Join("testdata", "module"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	gopath, err := filepath.Abs(filepath.Join("testdata", "resolve"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		bc   BuildContext
+		dir  string
+		conf *Config
+		want []resolvedImport
+	}{
+		{
+			name: "module",
+			bc:   NewBuildContext(module, WithGOPATH(filepath.Join(module, "not-exist"))),
+			dir:  filepath.Join(module, "domain"),
+			conf: &Config{
+				Layers: map[string]LayerConfig{
+					"domain":         {},
+					"infrastructure": {Allow: []string{"domain"}},
+					"orm":            {Packages: []string{"vendor/github.com/x/orm/..."}},
+				},
+			},
+			want: []resolvedImport{
+				{"example.com/shop/infrastructure", ProjectImport},
+				{"example.com/shop/vendor/github.com/x/orm", ThirdPartyImport},
+			},
+		},
+		{
+			name: "gopath",
+			bc:   NewBuildContext(filepath.Join(gopath, "src", "app"), WithGOROOT(""), WithGOPATH(gopath)),
+			dir:  filepath.Join(gopath, "src", "app", "cmd"),
+			conf: &Config{
+				Layers: map[string]LayerConfig{
+					"cmd": {Packages: []string{"cmd/..."}},
+					"lib": {Packages: []string{"vendor/lib/..."}},
+				},
+			},
+			// the other GOPATH package is not checked without the pattern.
+			want: []resolvedImport{{"app/vendor/lib", ThirdPartyImport}},
+		},
+	}
+	for _, tt := range tests {
+		fset := token.NewFileSet()
+		pkgs, err := ParseDir(fset, &tt.bc, tt.dir, nil, parser.ImportsOnly)
+		if err != nil {
+			t.Fatalf("%s: ParseDir() error = %v", tt.name, err)
+		}
+		violations, err := CheckDependency(fset, &tt.bc, pkgs, tt.conf)
+		if err != nil {
+			t.Errorf("%s: CheckDependency() error = %v", tt.name, err)
+		}
+
+		var got []resolvedImport
+		for _, v := range violations {
+			got = append(got, resolvedImport{v.Resolved, v.Class})
+		}
+		sort.Slice(got, func(i, j int) bool { return got[i].path < got[j].path })
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: CheckDependency() resolved = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+type resolvedImport struct {
+	path  string
+	class ImportClass
+}
@@ -0,0 +1,122 @@
+// Copyright 2017 The go-importlint Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package importlint
+
+import (
+	"bufio"
+	"go/build"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/buildutil"
+)
+
+// Project returns the import path and the root directory of the linting project.
+//
+// The import path is conf.Project if set. Otherwise it is inferred from the
+// module path of go.mod, the import comment of the root package or the GOPATH
+// location of the root directory in that order.
+func (bc *BuildContext) Project(conf *Config) (importPath, dir string) {
+	modPath, modDir := findGoMod(bc.root)
+
+	if conf != nil && conf.Project != "" {
+		if modPath == conf.Project {
+			return conf.Project, modDir
+		}
+		for _, gopath := range bc.gopaths {
+			if dir := filepath.Join(srcDir(gopath), filepath.FromSlash(conf.Project)); buildutil.IsDir(bc.ctxt, dir) {
+				return conf.Project, dir
+			}
+		}
+		return conf.Project, bc.root
+	}
+
+	if modPath != "" {
+		return modPath, modDir
+	}
+	if pkg, err := bc.ctxt.ImportDir(bc.root, build.ImportComment); err == nil && pkg.ImportComment != "" {
+		return pkg.ImportComment, bc.root
+	}
+	for _, gopath := range bc.gopaths {
+		if rel, ok := buildutil.HasSubdir(bc.ctxt, srcDir(gopath), bc.root); ok {
+			return rel, bc.root
+		}
+	}
+	return "", bc.root
+}
+
+// importPathOf returns the import path of the package in dir.
+// The dir in the project directory is relative to the project import path,
+// and the others are relative to the GOPATH.
+func (bc *BuildContext) importPathOf(dir, project, projectDir string) string {
+	if project != "" {
+		if dir == projectDir {
+			return project
+		}
+		if rel, ok := buildutil.HasSubdir(bc.ctxt, projectDir, dir); ok {
+			return path.Join(project, rel)
+		}
+	}
+	for _, gopath := range bc.gopaths {
+		if rel, ok := buildutil.HasSubdir(bc.ctxt, srcDir(gopath), dir); ok {
+			return rel
+		}
+	}
+	return ""
+}
+
+// moduleImportPath returns the import path of the package in dir of the module
+// modPath whose root directory is modDir.
+func moduleImportPath(modPath, modDir, dir string) string {
+	rel, err := filepath.Rel(modDir, dir)
+	if err != nil || rel == "." {
+		return modPath
+	}
+	return path.Join(modPath, filepath.ToSlash(rel))
+}
+
+// findGoMod searches go.mod from dir upward, and returns the module path and
+// the directory of go.mod.
+func findGoMod(dir string) (modPath, modDir string) {
+	for {
+		if modPath := parseModulePath(filepath.Join(dir, "go.mod")); modPath != "" {
+			return modPath, dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", ""
+		}
+		dir = parent
+	}
+}
+
+// parseModulePath returns the module path of the go.mod file, or empty if not found.
+func parseModulePath(filename string) string {
+	f, err := os.Open(filename)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if !strings.HasPrefix(line, "module") {
+			continue
+		}
+		modPath := strings.TrimSpace(strings.TrimPrefix(line, "module"))
+		if i := strings.Index(modPath, "//"); i >= 0 {
+			modPath = strings.TrimSpace(modPath[:i])
+		}
+		if unquoted, err := strconv.Unquote(modPath); err == nil {
+			modPath = unquoted
+		}
+		return modPath
+	}
+	return ""
+}
@@ -0,0 +1,117 @@
+// Copyright 2017 The go-importlint Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package importlint
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// ConfigResolver resolves the Config which governs each package directory.
+//
+// The config files in the subdirectories of the root directory override or
+// extend the config of the parent directory, with the same merge semantics as
+// the Include config. The components are defined for the whole project, so the
+// nested config files must not change them.
+type ConfigResolver struct {
+	root     string
+	rootPath string
+	rootConf *Config
+
+	mu    sync.Mutex
+	cache map[string]resolvedConfig
+}
+
+type resolvedConfig struct {
+	conf *Config
+	path string
+}
+
+// NewConfigResolver returns the ConfigResolver of the root directory.
+// The conf parsed from path governs the root directory.
+func NewConfigResolver(root, path string, conf *Config) *ConfigResolver {
+	if abs, err := filepath.Abs(root); err == nil {
+		root = abs
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	return &ConfigResolver{
+		root:     root,
+		rootPath: path,
+		rootConf: conf,
+		cache:    make(map[string]resolvedConfig),
+	}
+}
+
+// Resolve returns the Config governing dir and the path of the nearest config file.
+// The directories outside of the root directory are governed by the root config.
+func (r *ConfigResolver) Resolve(dir string) (*Config, string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "could not get absolute path of %s", dir)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rc, err := r.resolve(dir)
+	if err != nil {
+		return nil, "", err
+	}
+	return rc.conf, rc.path, nil
+}
+
+func (r *ConfigResolver) resolve(dir string) (resolvedConfig, error) {
+	if rc, ok := r.cache[dir]; ok {
+		return rc, nil
+	}
+	if dir == r.root || !hasPathPrefix(dir, r.root) {
+		return resolvedConfig{conf: r.rootConf, path: r.rootPath}, nil
+	}
+
+	parent, err := r.resolve(filepath.Dir(dir))
+	if err != nil {
+		return resolvedConfig{}, err
+	}
+
+	rc := parent
+	for _, name := range ConfigFileNames {
+		path := filepath.Join(dir, name)
+		if path == r.rootPath || isNotExist(path) {
+			continue
+		}
+		nested, err := ParseConfig(path)
+		if err != nil {
+			return resolvedConfig{}, err
+		}
+		conf := parent.conf.clone()
+		conf.merge(nested)
+		if !sameComponents(conf.Components, parent.conf.Components) {
+			return resolvedConfig{}, errors.Errorf("%s: components can be defined only in the root config %s", path, r.rootPath)
+		}
+		rc = resolvedConfig{conf: conf, path: path}
+		break
+	}
+
+	r.cache[dir] = rc
+	return rc, nil
+}
+
+// sameComponents reports whether a and b define the same components, where the
+// nil and empty maps are the same.
+func sameComponents(a, b map[string][]string) bool {
+	return len(a) == 0 && len(b) == 0 || reflect.DeepEqual(a, b)
+}
+
+// hasPathPrefix reports whether path is in the prefix directory.
+func hasPathPrefix(path, prefix string) bool {
+	rel, err := filepath.Rel(prefix, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,68 @@
+// Copyright 2017 The go-importlint Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package importlint
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestConfigResolver(t *testing.T) {
+	root := filepath.Join("testdata", "resolver")
+	rootPath := filepath.Join(root, ".importlint.yaml")
+	conf, err := ParseConfig(rootPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := NewConfigResolver(root, rootPath, conf)
+
+	tests := []struct {
+		dir          string
+		wantMaxDepth int
+		wantErr      string
+	}{
+		{dir: root, wantMaxDepth: 2},
+		{dir: filepath.Join(root, "tools"), wantMaxDepth: 5},
+		{dir: filepath.Join(root, "tools", "sub"), wantMaxDepth: 5},
+		{dir: filepath.Join(root, "bad"), wantErr: "components can be defined only in the root config"},
+	}
+	for _, tt := range tests {
+		got, _, err := r.Resolve(tt.dir)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Resolve(%s) error = %v, want %q", tt.dir, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Resolve(%s) error = %v", tt.dir, err)
+			continue
+		}
+		if maxDepth := got.Layers["application"].MaxDepth; maxDepth != tt.wantMaxDepth {
+			t.Errorf("Resolve(%s) maxDepth = %d, want %d", tt.dir, maxDepth, tt.wantMaxDepth)
+		}
+		if len(got.Components) != 1 {
+			t.Errorf("Resolve(%s) components = %v, want the root ones", tt.dir, got.Components)
+		}
+	}
+}
+
+func TestSameComponents(t *testing.T) {
+	tests := []struct {
+		a, b map[string][]string
+		want bool
+	}{
+		{nil, map[string][]string{}, true},
+		{map[string][]string{"a": {"a/..."}}, map[string][]string{"a": {"a/..."}}, true},
+		{nil, map[string][]string{"a": {"a/..."}}, false},
+		{map[string][]string{"a": {"a/..."}}, map[string][]string{"a": {"b/..."}}, false},
+	}
+	for _, tt := range tests {
+		if got := sameComponents(tt.a, tt.b); got != tt.want {
+			t.Errorf("sameComponents(%v, %v) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
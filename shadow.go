@@ -0,0 +1,102 @@
+// Copyright 2017 The go-importlint Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package importlint
+
+import (
+	"fmt"
+	"go/build"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/buildutil"
+)
+
+// Shadow represents the import path which exists in multiple directories.
+// The linted code may differ from what is built in that case.
+type Shadow struct {
+	ImportPath string
+	// Dirs is the list of directories which have the ImportPath package.
+	Dirs []string
+	// Vendor reports whether the package is also vendored in the vendor directory.
+	Vendor bool
+}
+
+func (s Shadow) String() string {
+	if s.Vendor {
+		return fmt.Sprintf("%s is shadowed by vendor directory: %s", s.ImportPath, strings.Join(s.Dirs, ", "))
+	}
+	return fmt.Sprintf("%s exists in multiple GOPATH entries: %s", s.ImportPath, strings.Join(s.Dirs, ", "))
+}
+
+// FindShadows returns the import paths of pkgs which also exist in the other
+// GOPATH entries or the vendor directories.
+func (bc *BuildContext) FindShadows(pkgs []*build.Package) []Shadow {
+	byPath := make(map[string][]string)
+	vendored := make(map[string]bool)
+	for _, pkg := range pkgs {
+		if pkg.ImportPath == "" || pkg.ImportPath == "." {
+			continue
+		}
+		path := pkg.ImportPath
+		if i := strings.LastIndex(path, "/vendor/"); i >= 0 {
+			path = path[i+len("/vendor/"):]
+			vendored[path] = true
+		} else if strings.HasPrefix(path, "vendor/") {
+			path = path[len("vendor/"):]
+			vendored[path] = true
+		}
+		byPath[path] = appendDir(byPath[path], pkg.Dir)
+	}
+
+	for path, dirs := range byPath {
+		for _, gopath := range bc.gopaths {
+			dir := filepath.Join(srcDir(gopath), filepath.FromSlash(path))
+			if buildutil.IsDir(bc.ctxt, dir) && hasGoFiles(bc.ctxt, dir) {
+				dirs = appendDir(dirs, dir)
+			}
+		}
+		byPath[path] = dirs
+	}
+
+	var shadows []Shadow
+	for path, dirs := range byPath {
+		if len(dirs) < 2 {
+			continue
+		}
+		shadows = append(shadows, Shadow{
+			ImportPath: path,
+			Dirs:       dirs,
+			Vendor:     vendored[path],
+		})
+	}
+	sort.Slice(shadows, func(i, j int) bool {
+		return shadows[i].ImportPath < shadows[j].ImportPath
+	})
+
+	return shadows
+}
+
+func appendDir(dirs []string, dir string) []string {
+	for _, d := range dirs {
+		if d == dir {
+			return dirs
+		}
+	}
+	return append(dirs, dir)
+}
+
+func hasGoFiles(ctxt *build.Context, dir string) bool {
+	list, err := buildutil.ReadDir(ctxt, dir)
+	if err != nil {
+		return false
+	}
+	for _, fi := range list {
+		if !fi.IsDir() && strings.HasSuffix(fi.Name(), ".go") {
+			return true
+		}
+	}
+	return false
+}
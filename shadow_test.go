@@ -0,0 +1,46 @@
+// Copyright 2017 The go-importlint Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package importlint
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestFindShadows(t *testing.T) {
+	dir, err := filepath.Abs(filepath.Join("testdata", "shadow"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	gopath1, gopath2 := filepath.Join(dir, "gopath1"), filepath.Join(dir, "gopath2")
+
+	bc := NewBuildContext(filepath.Join(gopath1, "src", "app"), WithGOROOT(""), WithGOPATH(gopath1, gopath2))
+	pkgs, err := bc.FindAllPackage(nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Shadow{
+		{
+			ImportPath: "app/lib",
+			Dirs: []string{
+				filepath.Join(gopath1, "src", "app", "lib"),
+				filepath.Join(gopath2, "src", "app", "lib"),
+			},
+		},
+		{
+			ImportPath: "github.com/x/dep",
+			Dirs: []string{
+				filepath.Join(gopath1, "src", "app", "vendor", "github.com", "x", "dep"),
+				filepath.Join(gopath2, "src", "github.com", "x", "dep"),
+			},
+			Vendor: true,
+		},
+	}
+	if got := bc.FindShadows(pkgs); !reflect.DeepEqual(got, want) {
+		t.Errorf("FindShadows() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,10 @@
+package main
+
+import (
+	_ "fmt"
+
+	_ "github.com/x/dep"
+	_ "svc/lib"
+)
+
+func main() {}
@@ -0,0 +1,3 @@
+package application
+
+import _ "shop/domain"
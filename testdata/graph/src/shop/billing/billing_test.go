@@ -0,0 +1,3 @@
+package billing_test
+
+import _ "shop/payments"
@@ -0,0 +1,3 @@
+package infrastructure
+
+import _ "shop/application"
@@ -0,0 +1,3 @@
+package payments
+
+import _ "shop/billing"
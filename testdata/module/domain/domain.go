@@ -0,0 +1,8 @@
+package domain
+
+import (
+	_ "fmt"
+
+	_ "example.com/shop/infrastructure"
+	_ "github.com/x/orm"
+)
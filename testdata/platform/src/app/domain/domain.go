@@ -0,0 +1,3 @@
+package domain
+
+import _ "app/application"
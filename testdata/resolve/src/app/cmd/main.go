@@ -0,0 +1,9 @@
+package main
+
+import (
+	_ "lib"
+	_ "other"
+	_ "shared"
+)
+
+func main() {}
@@ -0,0 +1,8 @@
+package main
+
+import (
+	_ "app/lib"
+	_ "github.com/x/dep"
+)
+
+func main() {}
@@ -0,0 +1,8 @@
+package main
+
+import (
+	_ "github.com/a/lib"
+	_ "github.com/c/notlocked"
+)
+
+func main() {}
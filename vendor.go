@@ -0,0 +1,103 @@
+// Copyright 2017 The go-importlint Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package importlint
+
+import (
+	"go/build"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/tools/go/buildutil"
+)
+
+// ResolveImport resolves the import path imported from the fromDir directory
+// following the go tool's vendor lookup.
+// Returns the import path and the directory which the import resolved to.
+//
+// The nearest enclosing vendor directory wins up to the src/vendor directory of
+// the tree containing fromDir, and then GOROOT and each GOPATH entries are searched
+// in order. If fromDir is in a module out of GOROOT and GOPATH, the packages of the
+// module and the vendor directory of the module root are searched before GOPATH,
+// and the vendored import path has the "<module>/vendor/" prefix.
+func (bc *BuildContext) ResolveImport(importPath, fromDir string) (resolved, dir string, ok bool) {
+	resolved, dir, _, ok = bc.lookupImport(importPath, fromDir)
+	return resolved, dir, ok
+}
+
+// lookupImport is ResolveImport which also returns the list of directories searched in order.
+func (bc *BuildContext) lookupImport(importPath, fromDir string) (resolved, dir string, searched []string, ok bool) {
+	try := func(dir string) bool {
+		searched = append(searched, dir)
+		return bc.isPackageDir(dir)
+	}
+
+	roots := bc.gopaths
+	if bc.ctxt.GOROOT != "" {
+		roots = append([]string{bc.ctxt.GOROOT}, roots...)
+	}
+	inTree := false
+	for _, root := range roots {
+		src := srcDir(root)
+		rel, ok := buildutil.HasSubdir(bc.ctxt, src, fromDir)
+		if !ok {
+			continue
+		}
+		inTree = true
+
+		// The vendor directory directly under the src is searched too, the same
+		// as go/build and the go command.
+		parts := strings.Split(rel, "/")
+		for i := len(parts); i >= 0; i-- {
+			parent := path.Join(parts[:i]...)
+			vendored := path.Join(parent, "vendor", importPath)
+			if dir := filepath.Join(src, filepath.FromSlash(vendored)); try(dir) {
+				return vendored, dir, searched, true
+			}
+		}
+		break
+	}
+
+	if bc.ctxt.GOROOT != "" {
+		if dir := filepath.Join(bc.ctxt.GOROOT, "src", filepath.FromSlash(importPath)); try(dir) {
+			return importPath, dir, searched, true
+		}
+	}
+	if !inTree {
+		if modPath, modDir := findGoMod(fromDir); modPath != "" {
+			if hasImportPathPrefix(importPath, modPath) {
+				if dir := filepath.Join(modDir, filepath.FromSlash(strings.TrimPrefix(importPath, modPath))); try(dir) {
+					return importPath, dir, searched, true
+				}
+			} else if dir := filepath.Join(modDir, "vendor", filepath.FromSlash(importPath)); try(dir) {
+				return path.Join(modPath, "vendor", importPath), dir, searched, true
+			}
+		}
+	}
+	for _, gopath := range bc.gopaths {
+		if dir := filepath.Join(srcDir(gopath), filepath.FromSlash(importPath)); try(dir) {
+			return importPath, dir, searched, true
+		}
+	}
+
+	return "", "", searched, false
+}
+
+// findImport is lookupImport which falls back to build.Context.Import with FindOnly,
+// to find the packages which are not in GOPATH such as the module dependencies.
+func (bc *BuildContext) findImport(importPath, fromDir string) (resolved, dir string, searched []string, ok bool) {
+	resolved, dir, searched, ok = bc.lookupImport(importPath, fromDir)
+	if ok {
+		return resolved, dir, searched, true
+	}
+	if pkg, err := bc.ctxt.Import(importPath, fromDir, build.FindOnly); err == nil && pkg.Dir != "" && bc.isPackageDir(pkg.Dir) {
+		return pkg.ImportPath, pkg.Dir, searched, true
+	}
+	return "", "", searched, false
+}
+
+func (bc *BuildContext) isPackageDir(dir string) bool {
+	return buildutil.IsDir(bc.ctxt, dir) && hasGoFiles(bc.ctxt, dir)
+}
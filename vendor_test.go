@@ -0,0 +1,81 @@
+// Copyright 2017 The go-importlint Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package importlint
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLookupImport(t *testing.T) {
+	gopath, err := filepath.Abs(filepath.Join("testdata", "resolve"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := filepath.Join(gopath, "src")
+	bc := NewBuildContext(filepath.Join(src, "app"), WithGOROOT(""), WithGOPATH(gopath))
+	from := filepath.Join(src, "app", "cmd")
+
+	tests := []struct {
+		importPath   string
+		wantResolved string
+		wantDir      string
+		wantOK       bool
+		wantSearched []string
+	}{
+		{
+			importPath:   "lib",
+			wantResolved: "app/vendor/lib",
+			wantDir:      filepath.Join(src, "app", "vendor", "lib"),
+			wantOK:       true,
+			wantSearched: []string{
+				filepath.Join(src, "app", "cmd", "vendor", "lib"),
+				filepath.Join(src, "app", "vendor", "lib"),
+			},
+		},
+		{
+			importPath:   "shared",
+			wantResolved: "vendor/shared",
+			wantDir:      filepath.Join(src, "vendor", "shared"),
+			wantOK:       true,
+			wantSearched: []string{
+				filepath.Join(src, "app", "cmd", "vendor", "shared"),
+				filepath.Join(src, "app", "vendor", "shared"),
+				filepath.Join(src, "vendor", "shared"),
+			},
+		},
+		{
+			importPath:   "other",
+			wantResolved: "other",
+			wantDir:      filepath.Join(src, "other"),
+			wantOK:       true,
+			wantSearched: []string{
+				filepath.Join(src, "app", "cmd", "vendor", "other"),
+				filepath.Join(src, "app", "vendor", "other"),
+				filepath.Join(src, "vendor", "other"),
+				filepath.Join(src, "other"),
+			},
+		},
+		{
+			importPath: "missing",
+			wantSearched: []string{
+				filepath.Join(src, "app", "cmd", "vendor", "missing"),
+				filepath.Join(src, "app", "vendor", "missing"),
+				filepath.Join(src, "vendor", "missing"),
+				filepath.Join(src, "missing"),
+			},
+		},
+	}
+	for _, tt := range tests {
+		resolved, dir, searched, ok := bc.lookupImport(tt.importPath, from)
+		if resolved != tt.wantResolved || dir != tt.wantDir || ok != tt.wantOK {
+			t.Errorf("lookupImport(%q) = %q, %q, %v, want %q, %q, %v", tt.importPath, resolved, dir, ok, tt.wantResolved, tt.wantDir, tt.wantOK)
+		}
+		if !reflect.DeepEqual(searched, tt.wantSearched) {
+			t.Errorf("lookupImport(%q) searched %q, want %q", tt.importPath, searched, tt.wantSearched)
+		}
+	}
+}
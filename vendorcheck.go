@@ -0,0 +1,287 @@
+// Copyright 2017 The go-importlint Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package importlint
+
+import (
+	"bufio"
+	"fmt"
+	"go/build"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/pkg/errors"
+)
+
+// VendorIssueKind is the kind of VendorIssue.
+type VendorIssueKind int
+
+const (
+	// UnusedVendor is the vendored package which nothing imports.
+	UnusedVendor VendorIssueKind = iota
+	// NotLocked is the imported vendored package which is absent from the lock file.
+	NotLocked
+	// MissingVendor is the lock file entry which is missing from the vendor directory.
+	MissingVendor
+)
+
+func (k VendorIssueKind) String() string {
+	switch k {
+	case UnusedVendor:
+		return "unused vendored package"
+	case NotLocked:
+		return "vendored package is not in the lock file"
+	case MissingVendor:
+		return "locked package is missing from vendor"
+	}
+	return fmt.Sprintf("VendorIssueKind(%d)", int(k))
+}
+
+// VendorIssue represents the inconsistency between the vendor directory,
+// the lock file and the actual imports.
+type VendorIssue struct {
+	Kind       VendorIssueKind
+	ImportPath string
+	LockFile   string
+}
+
+func (i VendorIssue) String() string {
+	return fmt.Sprintf("%s: %s: %s", i.LockFile, i.Kind, i.ImportPath)
+}
+
+// lockFiles is the list of lock file names relative to the project root.
+var lockFiles = []string{
+	"Gopkg.lock",
+	filepath.Join("vendor", "modules.txt"),
+}
+
+// lock represents the packages listed in the lock file.
+type lock struct {
+	filename string
+	packages map[string]bool
+	// prefix reports whether the packages are the repository roots which lock
+	// all packages under that, such as the gb-vendor manifest.
+	prefix bool
+	// external is the list of repository roots resolved outside of the vendor directory.
+	external []string
+}
+
+func (l *lock) has(importPath string) bool {
+	if l.packages[importPath] {
+		return true
+	}
+	if l.prefix {
+		for p := range l.packages {
+			if strings.HasPrefix(importPath, p+"/") {
+				return true
+			}
+		}
+	}
+	for _, p := range l.external {
+		if importPath == p || strings.HasPrefix(importPath, p+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckVendor compares the imports of pkgs with the packages listed in the lock file
+// and the vendor directory of the root directory.
+// The lock file is Gopkg.lock of dep, vendor/modules.txt of the go command or
+// vendor/manifest and depfile of gb.
+func (bc *BuildContext) CheckVendor(pkgs []*build.Package) ([]VendorIssue, error) {
+	vendorDir := filepath.Join(bc.root, "vendor")
+
+	l, err := bc.findLock()
+	if err != nil {
+		return nil, err
+	}
+	if bc.gb {
+		vendorDir = srcDir(vendorDir)
+	}
+
+	vendored, err := bc.vendorPackages(vendorDir)
+	if err != nil {
+		return nil, err
+	}
+
+	// collect the vendored packages which imported from pkgs transitively.
+	used := make(map[string]bool)
+	var visit func(imports []string)
+	visit = func(imports []string) {
+		for _, imp := range imports {
+			vpkg, ok := vendored[imp]
+			if !ok || used[imp] {
+				continue
+			}
+			used[imp] = true
+			visit(vpkg.Imports)
+		}
+	}
+	for _, pkg := range pkgs {
+		visit(pkg.Imports)
+		visit(pkg.TestImports)
+		visit(pkg.XTestImports)
+	}
+
+	var issues []VendorIssue
+	for imp := range vendored {
+		if !used[imp] {
+			issues = append(issues, VendorIssue{Kind: UnusedVendor, ImportPath: imp, LockFile: l.filename})
+		} else if !l.has(imp) {
+			issues = append(issues, VendorIssue{Kind: NotLocked, ImportPath: imp, LockFile: l.filename})
+		}
+	}
+	for imp := range l.packages {
+		if l.prefix {
+			if !isNotExist(filepath.Join(vendorDir, filepath.FromSlash(imp))) {
+				continue
+			}
+		} else if _, ok := vendored[imp]; ok {
+			continue
+		}
+		issues = append(issues, VendorIssue{Kind: MissingVendor, ImportPath: imp, LockFile: l.filename})
+	}
+	sort.Slice(issues, func(i, j int) bool {
+		if issues[i].Kind != issues[j].Kind {
+			return issues[i].Kind < issues[j].Kind
+		}
+		return issues[i].ImportPath < issues[j].ImportPath
+	})
+
+	return issues, nil
+}
+
+// findLock finds and parses the lock file of the root directory.
+func (bc *BuildContext) findLock() (*lock, error) {
+	if bc.gb {
+		p, err := bc.GbProject()
+		if err != nil {
+			return nil, err
+		}
+		if p.Manifest == nil && p.Depfile == nil {
+			return nil, errors.Errorf("could not find vendor/manifest or depfile in gb project %s", bc.root)
+		}
+		l := &lock{
+			filename: filepath.Join(bc.root, "vendor", "manifest"),
+			packages: make(map[string]bool),
+			prefix:   true,
+		}
+		if p.Manifest != nil {
+			for _, dep := range p.Manifest.Dependencies {
+				l.packages[dep.Importpath] = true
+			}
+		} else {
+			l.filename = filepath.Join(bc.root, "depfile")
+		}
+		for _, entry := range p.Depfile {
+			l.external = append(l.external, entry.Repository)
+		}
+		return l, nil
+	}
+
+	for _, name := range lockFiles {
+		filename := filepath.Join(bc.root, name)
+		if isNotExist(filename) {
+			continue
+		}
+		packages, err := parseLockFile(filename)
+		if err != nil {
+			return nil, err
+		}
+		return &lock{filename: filename, packages: packages}, nil
+	}
+
+	return nil, errors.Errorf("could not find the lock file in %s", bc.root)
+}
+
+// vendorPackages returns the packages in vendorDir keyed by the import path relative to vendorDir.
+func (bc *BuildContext) vendorPackages(vendorDir string) (map[string]*build.Package, error) {
+	pkgs := make(map[string]*build.Package)
+	err := filepath.Walk(vendorDir, func(p string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !fi.IsDir() {
+			return nil
+		}
+		if elem := fi.Name(); p != vendorDir && (strings.HasPrefix(elem, ".") || strings.HasPrefix(elem, "_") || elem == "testdata") {
+			return filepath.SkipDir
+		}
+
+		pkg, err := bc.ctxt.ImportDir(p, build.ImportMode(0))
+		if err != nil {
+			if _, ok := err.(*build.NoGoError); ok {
+				return nil
+			}
+		}
+		rel, err := filepath.Rel(vendorDir, p)
+		if err != nil || rel == "." {
+			return nil
+		}
+		pkgs[filepath.ToSlash(rel)] = pkg
+		return nil
+	})
+	if err != nil && !os.IsNotExist(err) {
+		return nil, errors.Wrapf(err, "could not walk %s", vendorDir)
+	}
+
+	return pkgs, nil
+}
+
+func parseLockFile(filename string) (map[string]bool, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not open %s", filename)
+	}
+	defer f.Close()
+
+	if filepath.Base(filename) == "modules.txt" {
+		return parseModulesTxt(f)
+	}
+	return parseGopkgLock(f)
+}
+
+// parseModulesTxt parses the vendor/modules.txt and returns the listed packages.
+func parseModulesTxt(r io.Reader) (map[string]bool, error) {
+	pkgs := make(map[string]bool)
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		pkgs[line] = true
+	}
+	return pkgs, sc.Err()
+}
+
+// gopkgLock is the part of the Gopkg.lock of dep used by parseGopkgLock.
+type gopkgLock struct {
+	Projects []struct {
+		Name     string   `toml:"name"`
+		Packages []string `toml:"packages"`
+	} `toml:"projects"`
+}
+
+// parseGopkgLock parses the Gopkg.lock of dep and returns the packages of each projects.
+func parseGopkgLock(r io.Reader) (map[string]bool, error) {
+	var l gopkgLock
+	if _, err := toml.DecodeReader(r, &l); err != nil {
+		return nil, errors.Wrap(err, "could not decode Gopkg.lock")
+	}
+
+	pkgs := make(map[string]bool)
+	for _, p := range l.Projects {
+		for _, pkg := range p.Packages {
+			pkgs[path.Join(p.Name, pkg)] = true
+		}
+	}
+	return pkgs, nil
+}
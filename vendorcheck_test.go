@@ -0,0 +1,194 @@
+// Copyright 2017 The go-importlint Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package importlint
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseGopkgLock(t *testing.T) {
+	tests := []struct {
+		name string
+		lock string
+		want map[string]bool
+	}{
+		{
+			name: "inline packages",
+			lock: `
+[[projects]]
+  name = "github.com/pkg/errors"
+  packages = ["."]
+  revision = "645ef00459ed84a119197bfb8d8205042c6df63d"
+  version = "v0.8.0"
+
+[[projects]]
+  name = "golang.org/x/tools"
+  packages = ["go/analysis","go/buildutil"]
+  revision = "c5643e9baf7fed6936d70e3abf925f86fa895ca1"
+  version = "v0.18.0"
+
+[solve-meta]
+  inputs-digest = "61b9d2342cbab45e0c6747f13e2ee22f058cc473eb097b917305420c9e239b12"
+`,
+			want: map[string]bool{
+				"github.com/pkg/errors":           true,
+				"golang.org/x/tools/go/analysis":  true,
+				"golang.org/x/tools/go/buildutil": true,
+			},
+		},
+		{
+			name: "multi-line packages",
+			lock: `
+[[projects]]
+  digest = "1:abc"
+  name = "github.com/a/lib"
+  packages = [
+    ".",
+    "sub",
+  ]
+  pruneopts = "UT"
+
+[solve-meta]
+  input-imports = [
+    "github.com/a/lib",
+    "github.com/not/project",
+  ]
+`,
+			want: map[string]bool{
+				"github.com/a/lib":     true,
+				"github.com/a/lib/sub": true,
+			},
+		},
+		{
+			name: "no projects",
+			lock: "[solve-meta]\n  solver-name = \"gps-cdcl\"\n",
+			want: map[string]bool{},
+		},
+	}
+	for _, tt := range tests {
+		got, err := parseGopkgLock(strings.NewReader(tt.lock))
+		if err != nil {
+			t.Errorf("%s: parseGopkgLock() error = %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: parseGopkgLock() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParseGopkgLockFile(t *testing.T) {
+	// the Gopkg.lock written by dep v0.5 with pruneopts, digest and input-imports.
+	got, err := parseLockFile(filepath.Join("testdata", "vendorcheck", "lock", "Gopkg.lock"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 39 {
+		t.Errorf("parseLockFile() returns %d packages, want 39", len(got))
+	}
+	for _, pkg := range []string{
+		"github.com/BurntSushi/toml",
+		"golang.org/x/mod/internal/lazyregexp",
+		"golang.org/x/sync/errgroup",
+		"golang.org/x/tools/go/analysis/analysistest",
+		"golang.org/x/tools/txtar",
+		"gopkg.in/yaml.v2",
+	} {
+		if !got[pkg] {
+			t.Errorf("parseLockFile() does not have %s", pkg)
+		}
+	}
+	if got["golang.org/x/mod"] || got["golang.org/x/tools"] {
+		t.Error("parseLockFile() has the project roots which are not locked packages")
+	}
+}
+
+func TestParseGopkgLockError(t *testing.T) {
+	if _, err := parseGopkgLock(strings.NewReader("[[projects]\n  name = \"github.com/a/lib\"\n")); err == nil {
+		t.Error("parseGopkgLock() error = nil, want the decode error")
+	}
+}
+
+func TestParseModulesTxt(t *testing.T) {
+	txt := `# github.com/a/lib v1.0.0
+## explicit; go 1.16
+github.com/a/lib
+github.com/a/lib/sub
+
+# github.com/b/other v0.1.0 => ../other
+github.com/b/other
+`
+	want := map[string]bool{
+		"github.com/a/lib":     true,
+		"github.com/a/lib/sub": true,
+		"github.com/b/other":   true,
+	}
+	got, err := parseModulesTxt(strings.NewReader(txt))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseModulesTxt() = %v, want %v", got, want)
+	}
+}
+
+func TestCheckVendor(t *testing.T) {
+	tests := []struct {
+		root     string
+		lockFile string
+		want     []VendorIssue
+	}{
+		{
+			root:     "dep",
+			lockFile: "Gopkg.lock",
+			want: []VendorIssue{
+				{Kind: UnusedVendor, ImportPath: "github.com/d/unused"},
+				{Kind: NotLocked, ImportPath: "github.com/c/notlocked"},
+				{Kind: MissingVendor, ImportPath: "github.com/b/missing"},
+			},
+		},
+		{
+			root:     "mod",
+			lockFile: filepath.Join("vendor", "modules.txt"),
+			want: []VendorIssue{
+				{Kind: UnusedVendor, ImportPath: "github.com/d/unused"},
+				{Kind: MissingVendor, ImportPath: "github.com/b/missing"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		root, err := filepath.Abs(filepath.Join("testdata", "vendorcheck", tt.root))
+		if err != nil {
+			t.Fatal(err)
+		}
+		bc := NewBuildContext(root)
+		pkgs, err := bc.FindAllPackage(nil, ExcludeVendor)
+		if err != nil {
+			t.Fatalf("%s: FindAllPackage() error = %v", tt.root, err)
+		}
+
+		got, err := bc.CheckVendor(pkgs)
+		if err != nil {
+			t.Errorf("%s: CheckVendor() error = %v", tt.root, err)
+			continue
+		}
+		for i := range tt.want {
+			tt.want[i].LockFile = filepath.Join(root, tt.lockFile)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: CheckVendor() = %v, want %v", tt.root, got, tt.want)
+		}
+	}
+}
+
+func TestCheckVendorNoLockFile(t *testing.T) {
+	bc := NewBuildContext(filepath.Join("testdata", "resolve", "src", "app"))
+	if _, err := bc.CheckVendor(nil); err == nil {
+		t.Error("CheckVendor() error = nil, want the missing lock file error")
+	}
+}